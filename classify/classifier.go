@@ -0,0 +1,52 @@
+package classify
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"sort"
+)
+
+// Classifier assigns topics to predictions from their title and details, combining every
+// matching rule with the topic from a trained model. Either may be nil.
+type Classifier struct {
+	rules []*Rule
+	model *Model
+}
+
+func NewClassifier(rules []*Rule, model *Model) *Classifier {
+	return &Classifier{
+		rules: rules,
+		model: model,
+	}
+}
+
+// Topics returns the sorted, distinct topics for a prediction.
+func (c *Classifier) Topics(p *predictions.PredictionSummary) (topics []string) {
+	text := p.Title
+	if p.Details.Text != "" {
+		text += "\n" + p.Details.Text
+	}
+
+	seen := make(map[string]bool)
+	for _, r := range c.rules {
+		if !seen[r.Topic] && r.Matches(text) {
+			seen[r.Topic] = true
+			topics = append(topics, r.Topic)
+		}
+	}
+	if c.model != nil {
+		topic, _ := c.model.Classify(text)
+		if topic != "" && !seen[topic] {
+			topics = append(topics, topic)
+		}
+	}
+
+	sort.Strings(topics)
+	return
+}
+
+// Classify sets the topics of each prediction.
+func (c *Classifier) Classify(ps []*predictions.PredictionSummary) {
+	for _, p := range ps {
+		p.Topics = c.Topics(p)
+	}
+}
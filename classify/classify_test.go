@@ -0,0 +1,155 @@
+package classify
+
+import (
+	"bytes"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testRules = `# Topics for testing
+politics election
+politics /\bpresident(ial)?\b/
+crypto	bitcoin
+`
+
+const testSamples = `politics,The Labour party will win the general election
+politics,The senate will pass the healthcare bill
+politics,The prime minister will resign before the vote
+sports,Manchester United will win the league
+sports,The Lakers will reach the playoffs this season
+sports,England will win the world cup
+`
+
+func TestParseRules(t *testing.T) {
+	t.Parallel()
+
+	rules, err := ParseRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("Error parsing rules: %s", err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("Incorrect number of rules; should be %d, was %d", 3, len(rules))
+	}
+
+	tests := []struct {
+		rule    int
+		text    string
+		matches bool
+	}{
+		{0, "Who will win the Election?", true},
+		{0, "Reelection campaign", false},
+		{1, "The presidential debate", true},
+		{1, "The presidency", false},
+		{2, "BITCOIN will crash", true},
+	}
+	for _, test := range tests {
+		if rules[test.rule].Matches(test.text) != test.matches {
+			t.Errorf("Incorrect match of rule %d against %q; should be %t", test.rule, test.text, test.matches)
+		}
+	}
+}
+
+func TestParseRulesInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, rules := range []string{"politics", "politics /(/"} {
+		_, err := ParseRules(strings.NewReader(rules))
+		if err == nil {
+			t.Errorf("Expected error parsing rules %q", rules)
+		}
+	}
+}
+
+func TestTrainClassify(t *testing.T) {
+	t.Parallel()
+
+	samples, err := ReadSamples(strings.NewReader(testSamples))
+	if err != nil {
+		t.Fatalf("Error reading samples: %s", err)
+	}
+	m, err := Train(samples)
+	if err != nil {
+		t.Fatalf("Error training model: %s", err)
+	}
+
+	tests := []struct {
+		text  string
+		topic string
+	}{
+		{"Will the senate vote to impeach the prime minister?", "politics"},
+		{"Arsenal will win the league next season", "sports"},
+		{"It will rain tomorrow", ""},
+	}
+	for _, test := range tests {
+		topic, _ := m.Classify(test.text)
+		if topic != test.topic {
+			t.Errorf("Incorrect topic for %q; should be %q, was %q", test.text, test.topic, topic)
+		}
+	}
+
+	// The model should classify the same after saving and loading
+	dir, err := ioutil.TempDir("", "classify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var buf bytes.Buffer
+	err = m.Save(&buf)
+	if err != nil {
+		t.Fatalf("Error saving model: %s", err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "model.json"), buf.Bytes(), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadModel(filepath.Join(dir, "model.json"))
+	if err != nil {
+		t.Fatalf("Error loading model: %s", err)
+	}
+	for _, test := range tests {
+		topic, _ := loaded.Classify(test.text)
+		if topic != test.topic {
+			t.Errorf("Incorrect topic from loaded model for %q; should be %q, was %q", test.text, test.topic, topic)
+		}
+	}
+}
+
+func TestClassifier(t *testing.T) {
+	t.Parallel()
+
+	rules, err := ParseRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("Error parsing rules: %s", err)
+	}
+	samples, err := ReadSamples(strings.NewReader(testSamples))
+	if err != nil {
+		t.Fatalf("Error reading samples: %s", err)
+	}
+	m, err := Train(samples)
+	if err != nil {
+		t.Fatalf("Error training model: %s", err)
+	}
+
+	ps := []*predictions.PredictionSummary{
+		{Title: "Bitcoin will be legal tender after the election"},
+		{Title: "The senate will confirm the nominee", Details: predictions.PredictionDetails{Text: "Buy bitcoin"}},
+		{Title: "It will rain tomorrow"},
+	}
+	NewClassifier(rules, m).Classify(ps)
+
+	expected := [][]string{
+		{"crypto", "politics"},
+		{"crypto", "politics"},
+		nil,
+	}
+	for i, p := range ps {
+		if strings.Join(p.Topics, ",") != strings.Join(expected[i], ",") {
+			t.Errorf("Incorrect topics for prediction %d; should be %v, was %v", i, expected[i], p.Topics)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package classify
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultMinSimilarity is the cosine similarity to its nearest centroid below which
+// a trained model assigns no topic to a text.
+const DefaultMinSimilarity = 0.1
+
+// Sample is a labelled text used to train a model.
+type Sample struct {
+	Topic string
+	Text  string
+}
+
+// Model is a TF-IDF nearest-centroid classifier. Each topic's centroid is the mean of the
+// normalised TF-IDF vectors of its samples, and a text is assigned the topic of the centroid
+// most similar to it.
+type Model struct {
+	Idf           map[string]float64            `json:"idf"`
+	Centroids     map[string]map[string]float64 `json:"centroids"`
+	MinSimilarity float64                       `json:"min_similarity"`
+}
+
+// Train builds a model from labelled samples. A text labelled with several topics
+// should be included once per topic.
+func Train(samples []*Sample) (*Model, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("no samples to train from")
+	}
+
+	m := &Model{
+		Idf:           make(map[string]float64),
+		Centroids:     make(map[string]map[string]float64),
+		MinSimilarity: DefaultMinSimilarity,
+	}
+
+	docFreq := make(map[string]int)
+	for _, s := range samples {
+		for term := range termFrequencies(s.Text) {
+			docFreq[term]++
+		}
+	}
+	for term, df := range docFreq {
+		m.Idf[term] = math.Log(float64(1+len(samples))/float64(1+df)) + 1
+	}
+
+	for _, s := range samples {
+		centroid, exists := m.Centroids[s.Topic]
+		if !exists {
+			centroid = make(map[string]float64)
+			m.Centroids[s.Topic] = centroid
+		}
+		for term, weight := range m.vector(s.Text) {
+			centroid[term] += weight
+		}
+	}
+	for _, centroid := range m.Centroids {
+		normalise(centroid)
+	}
+
+	return m, nil
+}
+
+// Classify returns the topic whose centroid is most similar to the text, and the
+// similarity, or an empty topic if none is at least the model's minimum similarity.
+func (m *Model) Classify(text string) (topic string, similarity float64) {
+	v := m.vector(text)
+
+	// Iterate in a fixed order so ties are broken consistently
+	var topics []string
+	for t := range m.Centroids {
+		topics = append(topics, t)
+	}
+	sort.Strings(topics)
+
+	for _, t := range topics {
+		s := dot(v, m.Centroids[t])
+		if s > similarity {
+			topic, similarity = t, s
+		}
+	}
+
+	if similarity < m.MinSimilarity {
+		return "", similarity
+	}
+	return
+}
+
+// vector returns the normalised TF-IDF vector of a text, ignoring terms the model
+// was not trained on.
+func (m *Model) vector(text string) map[string]float64 {
+	v := make(map[string]float64)
+	for term, tf := range termFrequencies(text) {
+		idf, exists := m.Idf[term]
+		if exists {
+			v[term] = float64(tf) * idf
+		}
+	}
+	normalise(v)
+	return v
+}
+
+func (m *Model) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(m)
+}
+
+func LoadModel(path string) (*Model, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m := new(Model)
+	err = json.NewDecoder(f).Decode(m)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ReadSamples reads labelled samples from CSV, with the topic in the first column
+// and the text in the second.
+func ReadSamples(r io.Reader) (samples []*Sample, err error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = 2
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, record := range records {
+		topic := strings.TrimSpace(record[0])
+		if topic == "" {
+			return nil, fmt.Errorf("record %d: missing topic", i+1)
+		}
+		samples = append(samples, &Sample{Topic: topic, Text: record[1]})
+	}
+	return samples, nil
+}
+
+// stopWords are too common in predictions to say anything about their topic.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"before": true, "by": true, "do": true, "for": true, "from": true, "has": true,
+	"have": true, "i": true, "in": true, "is": true, "it": true, "least": true, "my": true,
+	"next": true, "not": true, "of": true, "on": true, "or": true, "than": true, "that": true,
+	"the": true, "there": true, "this": true, "to": true, "will": true, "with": true,
+}
+
+func termFrequencies(text string) map[string]int {
+	tf := make(map[string]int)
+	for _, term := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		if !stopWords[term] {
+			tf[term]++
+		}
+	}
+	return tf
+}
+
+func normalise(v map[string]float64) {
+	length := math.Sqrt(dot(v, v))
+	if length == 0 {
+		return
+	}
+	for term := range v {
+		v[term] /= length
+	}
+}
+
+func dot(a, b map[string]float64) (sum float64) {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	for term, weight := range a {
+		sum += weight * b[term]
+	}
+	return
+}
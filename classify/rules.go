@@ -0,0 +1,84 @@
+package classify
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Rule assigns a topic to any text its pattern matches.
+type Rule struct {
+	Topic   string
+	pattern *regexp.Regexp
+}
+
+func NewKeywordRule(topic, keyword string) *Rule {
+	return &Rule{
+		Topic:   topic,
+		pattern: regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(keyword) + `\b`),
+	}
+}
+
+func NewRegexpRule(topic, expr string) (*Rule, error) {
+	pattern, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Rule{Topic: topic, pattern: pattern}, nil
+}
+
+func (r *Rule) Matches(text string) bool {
+	return r.pattern.MatchString(text)
+}
+
+// ParseRules reads rules one per line, as a topic followed by whitespace and a pattern.
+// Patterns between slashes are regular expressions, and anything else is a keyword or
+// phrase matched as whole words; both are case-insensitive. Blank lines and lines
+// starting with # are ignored.
+func ParseRules(r io.Reader) (rules []*Rule, err error) {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		split := strings.IndexFunc(text, unicode.IsSpace)
+		if split == -1 {
+			return nil, fmt.Errorf("line %d: expected a topic followed by a pattern", line)
+		}
+		topic, pattern := text[:split], strings.TrimSpace(text[split:])
+
+		if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			rule, err := NewRegexpRule(topic, pattern[1:len(pattern)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", line, err)
+			}
+			rules = append(rules, rule)
+		} else {
+			rules = append(rules, NewKeywordRule(topic, pattern))
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func LoadRules(path string) ([]*Rule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseRules(f)
+}
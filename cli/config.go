@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/htmlfetcher"
+	"github.com/jbeshir/predictionbook-extractor/logging"
+	"github.com/jbeshir/predictionbook-extractor/metrics"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"golang.org/x/time/rate"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// envPrefix is prepended to the upper-cased name of each setting to give its environment variable.
+const envPrefix = "PREDICTIONBOOK_"
+
+// config holds the settings shared between commands. Each is taken from, in increasing
+// priority, its default, the config file, the environment, and command line flags.
+type config struct {
+	Url          string
+	ApiToken     string
+	RateLimit    float64
+	Burst        int
+	Adaptive     bool
+	IgnoreRobots bool
+	Concurrency  int
+	StoreDir     string
+	OutputDir    string
+	MetricsAddr  string
+	MetricsFile  string
+	LogFormat    string
+	LogLevel     string
+
+	// Set by startMetrics, and used to instrument fetchers and sources created afterwards
+	registry *metrics.Registry
+}
+
+func defaultConfig() *config {
+	return &config{
+		Url:         "https://predictionbook.com",
+		RateLimit:   1,
+		Burst:       2,
+		Concurrency: 2,
+		StoreDir:    "store",
+		OutputDir:   ".",
+		LogFormat:   "text",
+		LogLevel:    "warn",
+	}
+}
+
+var configKeys = []string{"url", "api_token", "rate_limit", "burst", "adaptive", "ignore_robots", "concurrency", "store_dir", "output_dir", "metrics_addr", "metrics_file", "log_format", "log_level"}
+
+// set assigns a setting by its config file key.
+func (c *config) set(key, value string) (err error) {
+	switch key {
+	case "url":
+		c.Url = value
+	case "api_token":
+		c.ApiToken = value
+	case "rate_limit":
+		c.RateLimit, err = strconv.ParseFloat(value, 64)
+	case "burst":
+		c.Burst, err = strconv.Atoi(value)
+	case "adaptive":
+		c.Adaptive, err = strconv.ParseBool(value)
+	case "ignore_robots":
+		c.IgnoreRobots, err = strconv.ParseBool(value)
+	case "concurrency":
+		c.Concurrency, err = strconv.Atoi(value)
+	case "store_dir":
+		c.StoreDir = value
+	case "output_dir":
+		c.OutputDir = value
+	case "metrics_addr":
+		c.MetricsAddr = value
+	case "metrics_file":
+		c.MetricsFile = value
+	case "log_format":
+		c.LogFormat = value
+	case "log_level":
+		c.LogLevel = value
+	default:
+		return fmt.Errorf("unknown setting %s", key)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value for %s: %s", key, value)
+	}
+	return nil
+}
+
+// loadFile reads settings from a file of flat "key: value" or "key = value" lines, the
+// subset of YAML and TOML needed for top level settings. Values may be quoted, and # starts
+// a comment. Anything else, such as sections, nesting or lists, is rejected rather than
+// guessed at.
+func (c *config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		key, value, err := parseConfigLine(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("%s:%d: %s", path, line, err)
+		}
+		if key == "" {
+			continue
+		}
+
+		err = c.set(key, value)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %s", path, line, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseConfigLine returns the key and value set by a config file line, or an empty key
+// for blank lines, comments and YAML document markers.
+func parseConfigLine(line string) (key, value string, err error) {
+	text := strings.TrimSpace(line)
+	if text == "" || text == "---" || strings.HasPrefix(text, "#") {
+		return "", "", nil
+	}
+	if line[0] == ' ' || line[0] == '\t' {
+		return "", "", errors.New("indented lines are not supported; settings must be top level")
+	}
+	if strings.HasPrefix(text, "[") {
+		return "", "", errors.New("sections are not supported; settings must be top level")
+	}
+
+	split := strings.IndexAny(text, ":=")
+	if split == -1 {
+		return "", "", errors.New("expected a key and value")
+	}
+	key = strings.TrimSpace(text[:split])
+	if key == "" || strings.TrimLeft(key, "abcdefghijklmnopqrstuvwxyz_") != "" {
+		return "", "", fmt.Errorf("invalid key %q", key)
+	}
+
+	rest := strings.TrimSpace(text[split+1:])
+	switch {
+	case rest == "" || strings.HasPrefix(rest, "#"):
+		return "", "", fmt.Errorf("missing value for %s; nested settings are not supported", key)
+	case rest[0] == '"':
+		value, rest, err = parseDoubleQuoted(rest)
+	case rest[0] == '\'':
+		end := strings.Index(rest[1:], "'")
+		if end == -1 {
+			return "", "", fmt.Errorf("unterminated quoted value for %s", key)
+		}
+		value, rest = rest[1:end+1], rest[end+2:]
+	case rest[0] == '[' || rest[0] == '{':
+		return "", "", fmt.Errorf("lists and tables are not supported, as in the value for %s", key)
+	default:
+		// An unquoted value runs to a comment, which must follow whitespace
+		value = rest
+		for i := 1; i < len(rest); i++ {
+			if rest[i] == '#' && (rest[i-1] == ' ' || rest[i-1] == '\t') {
+				value = strings.TrimSpace(rest[:i])
+				break
+			}
+		}
+		rest = ""
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("invalid quoted value for %s: %s", key, err)
+	}
+
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", "", fmt.Errorf("unexpected %q after the value for %s", rest, key)
+	}
+	return key, value, nil
+}
+
+// parseDoubleQuoted parses a double-quoted string with backslash escapes at the start of s,
+// returning it and whatever follows.
+func parseDoubleQuoted(s string) (value, rest string, err error) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err = strconv.Unquote(s[:i+1])
+			return value, s[i+1:], err
+		}
+	}
+	return "", "", errors.New("unterminated string")
+}
+
+// loadEnv reads settings from environment variables named by envPrefix and the key,
+// e.g. PREDICTIONBOOK_RATE_LIMIT.
+func (c *config) loadEnv(getenv func(string) string) error {
+	for _, key := range configKeys {
+		value := getenv(envPrefix + strings.ToUpper(key))
+		if value == "" {
+			continue
+		}
+
+		err := c.set(key, value)
+		if err != nil {
+			return fmt.Errorf("%s%s: %s", envPrefix, strings.ToUpper(key), err)
+		}
+	}
+	return nil
+}
+
+// addSourceFlags registers flags overriding the settings used to retrieve predictions.
+func (c *config) addSourceFlags(flags *flag.FlagSet) {
+	flags.StringVar(&c.Url, "url", c.Url, "URL of PredictionBook instance to extract from")
+	flags.StringVar(&c.ApiToken, "apitoken", c.ApiToken, "Retrieve predictions through the JSON API using the given API token, instead of scraping HTML pages")
+	flags.Float64Var(&c.RateLimit, "ratelimit", c.RateLimit, "Maximum requests per second")
+	flags.IntVar(&c.Burst, "burst", c.Burst, "Maximum requests made at once before the rate limit applies")
+	flags.BoolVar(&c.Adaptive, "adaptive", c.Adaptive, "Back off from the rate limit when the server slows down or rate limits requests, recovering slowly")
+	flags.IntVar(&c.Concurrency, "concurrency", c.Concurrency, "Maximum concurrent requests")
+	flags.BoolVar(&c.IgnoreRobots, "ignorerobots", c.IgnoreRobots, "Ignore the instance's robots.txt; only for instances you have permission to crawl regardless")
+	flags.StringVar(&c.LogFormat, "logformat", c.LogFormat, "Format of logs written to stderr: text or json")
+	flags.StringVar(&c.LogLevel, "loglevel", c.LogLevel, "Minimum level of logs written to stderr: debug, info, warn or error")
+}
+
+// addStoreFlags registers flags overriding where the local store and output files are kept.
+func (c *config) addStoreFlags(flags *flag.FlagSet) {
+	flags.StringVar(&c.StoreDir, "storedir", c.StoreDir, "Directory of the local store, which keeps the most recent crawl and the history of earlier ones")
+	flags.StringVar(&c.OutputDir, "outputdir", c.OutputDir, "Directory relative output paths are written to")
+}
+
+// newFetcher returns a fetcher restricted to the configured instance, and unless
+// told otherwise, to what its robots.txt allows.
+func (c *config) newFetcher(ctx context.Context) (*htmlfetcher.Fetcher, error) {
+	limiter := rate.NewLimiter(rate.Limit(c.RateLimit), c.Burst)
+	var fetcher *htmlfetcher.Fetcher
+	if c.Adaptive {
+		fetcher = htmlfetcher.NewAdaptiveFetcher(limiter, c.Concurrency, c.newLogger())
+	} else {
+		fetcher = htmlfetcher.NewFetcher(limiter, c.Concurrency, c.newLogger())
+	}
+	if c.registry != nil {
+		fetcher.SetMetrics(htmlfetcher.NewMetrics(c.registry))
+	}
+
+	err := fetcher.Restrict(ctx, c.Url, c.IgnoreRobots)
+	if err != nil {
+		return nil, err
+	}
+	return fetcher, nil
+}
+
+// validate checks settings are usable, as invalid ones can otherwise block requests forever.
+func (c *config) validate() error {
+	if c.RateLimit <= 0 {
+		return fmt.Errorf("rate limit must be positive, was %g", c.RateLimit)
+	}
+	if c.Burst < 1 {
+		return fmt.Errorf("burst must be at least 1, was %d", c.Burst)
+	}
+	if c.Concurrency < 1 {
+		return fmt.Errorf("concurrency must be at least 1, was %d", c.Concurrency)
+	}
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		return fmt.Errorf("log format must be text or json, was %s", c.LogFormat)
+	}
+	var level slog.Level
+	err := level.UnmarshalText([]byte(c.LogLevel))
+	if err != nil {
+		return fmt.Errorf("log level must be debug, info, warn or error, was %s", c.LogLevel)
+	}
+	return nil
+}
+
+// newLogger returns a logger writing to stderr in the configured format and level.
+// The settings must have been validated.
+func (c *config) newLogger() logging.Logger {
+	var level slog.Level
+	level.UnmarshalText([]byte(c.LogLevel))
+
+	options := &slog.HandlerOptions{Level: level}
+	if c.LogFormat == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, options))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, options))
+}
+
+func (c *config) newSource(fetcher *htmlfetcher.Fetcher) (source predictions.PredictionSource) {
+	if c.ApiToken != "" {
+		source = predictions.NewApiSource(fetcher, c.Url, c.ApiToken, c.newLogger())
+	} else {
+		source = predictions.NewSource(fetcher, c.Url, c.newLogger())
+	}
+	if c.registry != nil {
+		source.SetMetrics(predictions.NewMetrics(c.registry))
+	}
+	return source
+}
+
+// outputPath resolves a path given for output relative to the output directory,
+// leaving empty paths, meaning no output, alone.
+func (c *config) outputPath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.OutputDir, path)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigPrecedence(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, content := range map[string]string{
+		"config.yaml": "---\n# Private instance\nurl: https://predictions.example.org\nrate_limit: 5\nburst: 10\nadaptive: true\nconcurrency: '4'\n",
+		"config.toml": "url = \"https://predictions.example.org\"\nrate_limit = 5\nburst = 10\nadaptive = true\nconcurrency = 4\n",
+	} {
+		path := filepath.Join(dir, name)
+		err = ioutil.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cfg := defaultConfig()
+		err = cfg.loadFile(path)
+		if err != nil {
+			t.Fatalf("Error loading %s: %s", name, err)
+		}
+		err = cfg.loadEnv(func(key string) string {
+			return map[string]string{
+				"PREDICTIONBOOK_CONCURRENCY": "8",
+				"PREDICTIONBOOK_OUTPUT_DIR":  "out",
+			}[key]
+		})
+		if err != nil {
+			t.Fatalf("Error loading environment: %s", err)
+		}
+
+		flags := newFlagSet("test")
+		cfg.addSourceFlags(flags)
+		cfg.addStoreFlags(flags)
+		err = flags.Parse([]string{"-ratelimit", "10"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cfg.Url != "https://predictions.example.org" {
+			t.Errorf("Incorrect URL from %s; should be %s, was %s", name, "https://predictions.example.org", cfg.Url)
+		}
+		if cfg.RateLimit != 10 {
+			t.Errorf("Incorrect rate limit from %s; should be %g, was %g", name, 10.0, cfg.RateLimit)
+		}
+		if cfg.Burst != 10 || !cfg.Adaptive {
+			t.Errorf("Incorrect burst or adaptive mode from %s; should be %d and %t, were %d and %t", name, 10, true, cfg.Burst, cfg.Adaptive)
+		}
+		if cfg.Concurrency != 8 {
+			t.Errorf("Incorrect concurrency from %s; should be %d, was %d", name, 8, cfg.Concurrency)
+		}
+		if cfg.StoreDir != "store" {
+			t.Errorf("Incorrect store dir from %s; should be %s, was %s", name, "store", cfg.StoreDir)
+		}
+		if cfg.outputPath("predictions.csv") != filepath.Join("out", "predictions.csv") {
+			t.Errorf("Incorrect output path from %s, was %s", name, cfg.outputPath("predictions.csv"))
+		}
+	}
+}
+
+func TestConfigInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, setting := range [][2]string{{"rate_limit", "fast"}, {"concurrency", "1.5"}, {"adaptive", "sometimes"}, {"colour", "blue"}} {
+		err := defaultConfig().set(setting[0], setting[1])
+		if err == nil {
+			t.Errorf("Expected error setting %s to %s", setting[0], setting[1])
+		}
+	}
+}
+
+func TestParseConfigLine(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line, key, value string
+	}{
+		{"# Comment", "", ""},
+		{"---", "", ""},
+		{"   ", "", ""},
+		{"url: https://example.org", "url", "https://example.org"},
+		{"url = \"https://example.org\"", "url", "https://example.org"},
+		{"rate_limit: 5 # per second", "rate_limit", "5"},
+		{"rate_limit = 5# not a comment", "rate_limit", "5# not a comment"},
+		{"api_token = \"a#b=c:d\" # token", "api_token", "a#b=c:d"},
+		{"output_dir: 'out: \"today\"'", "output_dir", "out: \"today\""},
+		{"output_dir = \"C:\\\\out\"", "output_dir", "C:\\out"},
+	}
+	for _, test := range tests {
+		key, value, err := parseConfigLine(test.line)
+		if err != nil {
+			t.Errorf("Error parsing %q should have been nil, was %s", test.line, err)
+			continue
+		}
+		if key != test.key || value != test.value {
+			t.Errorf("Incorrect setting from %q; should be %q = %q, was %q = %q", test.line, test.key, test.value, key, value)
+		}
+	}
+
+	for _, line := range []string{
+		"[fetcher]",
+		"  rate_limit: 5",
+		"fetcher:",
+		"topics = [\"a\", \"b\"]",
+		"url = \"https://example.org",
+		"url = \"https://example.org\" extra",
+		"\"url\" = \"https://example.org\"",
+		"just words",
+	} {
+		_, _, err := parseConfigLine(line)
+		if err == nil {
+			t.Errorf("Expected error parsing %q", line)
+		}
+	}
+}
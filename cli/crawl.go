@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/dedupe"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"github.com/jbeshir/predictionbook-extractor/store"
+	"os"
+	"time"
+)
+
+func crawlCommand(args []string, cfg *config) error {
+	flags := newFlagSet("crawl")
+	cfg.addSourceFlags(flags)
+	cfg.addStoreFlags(flags)
+	cfg.addMetricsFlags(flags)
+	saveStore := flags.Bool("store", true, "Save all predictions and responses retrieved to the local store; not allowed with -user, which retrieves only part of the site")
+	export := flags.String("export", "", "Export all predictions made in CSV format to the given file")
+	exportResponses := flags.String("exportresponses", "", "Export all prediction responses in CSV format to the given file")
+	exportFindings := flags.String("exportfindings", "", "Export disagreements between list page and prediction page summaries in CSV format to the given file")
+	exportDetails := flags.Bool("exportdetails", false, "Retrieve each prediction's page to include its details in the prediction export")
+	feeds := flags.String("feeds", "", "Write Atom and RSS feeds of recently created and judged predictions, and per-user activity, to the given directory")
+	user := flags.String("user", "", "Only retrieve predictions listed on the profile of the user with the given slug, and their responses")
+	topicRules := flags.String("topicrules", "", "Tag predictions with topics using the keyword and regular expression rules in the given file")
+	topicModel := flags.String("topicmodel", "", "Tag predictions with topics using the classifier model in the given file, as written by traintopics")
+	dedupeThreshold := flags.Float64("dedupe", 0, "Cluster near-duplicate predictions whose normalised titles have at least the given similarity, from 0 to 1")
+	showProgress := flags.Bool("progress", true, "Report progress to stderr, redrawn in place on a terminal or as periodic log lines otherwise")
+	progressInterval := flags.Duration("progressinterval", 30*time.Second, "Interval between progress log lines when stderr is not a terminal")
+	dedupeWindow := flags.Duration("dedupewindow", 7*24*time.Hour, "Maximum difference between deadlines of near-duplicate predictions; negative to ignore deadlines")
+	exportOptions := addExportFlags(flags)
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	err = cfg.validate()
+	if err != nil {
+		return usageError{err: err}
+	}
+	csv, err := exportOptions.parse()
+	if err != nil {
+		return err
+	}
+
+	// A single user's predictions would replace the whole stored dataset and its history
+	if *user != "" {
+		storeSet := false
+		flags.Visit(func(f *flag.Flag) {
+			storeSet = storeSet || f.Name == "store"
+		})
+		if storeSet && *saveStore {
+			return usageError{err: errors.New("-store can't be used with -user, as it would replace the stored dataset with one user's predictions")}
+		}
+		*saveStore = false
+	}
+
+	finishMetrics, err := cfg.startMetrics()
+	if err != nil {
+		return err
+	}
+	defer finishMetrics()
+
+	fetcher, err := cfg.newFetcher(context.Background())
+	if err != nil {
+		return fmt.Errorf("preparing to crawl: %s", err)
+	}
+	source := cfg.newSource(fetcher)
+	if *showProgress {
+		display := newProgressDisplay(os.Stderr, *progressInterval)
+		defer display.finish()
+		source.SetProgressFunc(display.report)
+	}
+
+	crawled := time.Now()
+	var ps []*predictions.PredictionSummary
+	if *user != "" {
+		htmlSource, ok := source.(*predictions.Source)
+		if !ok {
+			return usageError{err: errors.New("retrieving a single user's predictions is not supported through the JSON API")}
+		}
+		ps, err = htmlSource.AllUserPredictions(context.Background(), *user)
+	} else {
+		ps, err = source.AllPredictions(context.Background())
+	}
+	if err != nil {
+		return fmt.Errorf("retrieving predictions: %s", err)
+	}
+
+	var responses []*predictions.PredictionResponse
+	var findings []*predictions.DataQualityFinding
+	if *exportResponses != "" || *exportDetails || *exportFindings != "" || *feeds != "" || *saveStore {
+		var pageSummaries []*predictions.PredictionSummary
+		pageSummaries, responses, err = source.AllPredictionResponses(context.Background(), ps)
+		if err != nil {
+			return fmt.Errorf("retrieving prediction responses: %s", err)
+		}
+
+		// Export the canonical summaries, rather than those from the list pages alone
+		reconciled := predictions.ReconcileSummaries(ps, pageSummaries)
+		ps = nil
+		for _, r := range reconciled {
+			ps = append(ps, &r.PredictionSummary)
+			findings = append(findings, r.Findings...)
+		}
+	}
+
+	if *topicRules != "" || *topicModel != "" {
+		classifier, err := loadClassifier(*topicRules, *topicModel)
+		if err != nil {
+			return fmt.Errorf("loading topic classifier: %s", err)
+		}
+		classifier.Classify(ps)
+	}
+
+	if *dedupeThreshold > 0 {
+		dedupe.NewDetector(*dedupeThreshold, *dedupeWindow).Assign(ps)
+	}
+
+	err = writeFindingsCsv(cfg.outputPath(*exportFindings), findings, csv.findings)
+	if err != nil {
+		return err
+	}
+
+	if *saveStore {
+		err := store.NewStore(cfg.StoreDir).Save(&store.Dataset{
+			Crawled:     crawled,
+			BaseUrl:     cfg.Url,
+			Predictions: ps,
+			Responses:   responses,
+		})
+		if err != nil {
+			return fmt.Errorf("saving to store: %s", err)
+		}
+	}
+
+	if *feeds != "" {
+		err := writeFeeds(cfg.outputPath(*feeds), cfg.Url, crawled, ps, responses)
+		if err != nil {
+			return fmt.Errorf("writing feeds: %s", err)
+		}
+	}
+
+	err = writePredictionsCsv(cfg.outputPath(*export), ps, csv.predictions)
+	if err != nil {
+		return err
+	}
+	err = writeResponsesCsv(cfg.outputPath(*exportResponses), responses, csv.responses)
+	if err != nil {
+		return err
+	}
+	return writeDataPackage(cfg.outputPath(csv.dataPackage), csv, &store.Dataset{Crawled: crawled, BaseUrl: cfg.Url}, cfg.outputPath(*export), cfg.outputPath(*exportResponses), cfg.outputPath(*exportFindings))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"github.com/jbeshir/predictionbook-extractor/store"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCrawlUserLeavesStore(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/alice" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("<html><body></body></html>"))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "crawl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = store.NewStore(dir).Save(&store.Dataset{Crawled: time.Unix(1000, 0), BaseUrl: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := ioutil.ReadFile(filepath.Join(dir, "dataset.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := defaultConfig()
+	cfg.Url = server.URL
+	cfg.StoreDir = dir
+	cfg.RateLimit = 100
+	err = crawlCommand([]string{"-user", "alice", "-progress=false"}, cfg)
+	if err != nil {
+		t.Fatalf("Error crawling user: %s", err)
+	}
+
+	after, err := ioutil.ReadFile(filepath.Join(dir, "dataset.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Errorf("Crawling a single user should leave the stored dataset unchanged")
+	}
+
+	err = crawlCommand([]string{"-user", "alice", "-store", "-progress=false"}, cfg)
+	if _, ok := err.(usageError); !ok {
+		t.Errorf("Expected usage error for -store with -user, was %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/store"
+)
+
+func diffCommand(args []string, cfg *config) error {
+	flags := newFlagSet("diff")
+	cfg.addStoreFlags(flags)
+	oldResponses := flags.String("oldresponses", "", "Responses CSV export to read alongside an older predictions CSV export")
+	newResponses := flags.String("newresponses", "", "Responses CSV export to read alongside a newer predictions CSV export")
+	changeLog := flags.String("changelog", "", "Write every change as JSON lines to the given file")
+	listChanges := flags.Bool("list", true, "List each change other than new responses after the summary")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return usageError{err: errors.New("diff takes an older and newer dataset, each a store directory, dataset JSON file or predictions CSV export")}
+	}
+
+	previous, err := store.LoadFile(flags.Arg(0), *oldResponses)
+	if err != nil {
+		return fmt.Errorf("loading older dataset: %s", err)
+	}
+	current, err := store.LoadFile(flags.Arg(1), *newResponses)
+	if err != nil {
+		return fmt.Errorf("loading newer dataset: %s", err)
+	}
+
+	changes := store.Diff(previous, current)
+	fmt.Println(store.SummariseChanges(changes))
+	if *listChanges {
+		for _, c := range changes {
+			if c.Kind != store.ResponseAdded {
+				fmt.Println(c)
+			}
+		}
+	}
+
+	return writeChangeLog(cfg.outputPath(*changeLog), changes)
+}
+
+// writeChangeLog writes changes as JSON lines, doing nothing if path is empty.
+func writeChangeLog(path string, changes []*store.Change) error {
+	if path == "" {
+		return nil
+	}
+
+	f, err := createOutput(path)
+	if err != nil {
+		return fmt.Errorf("opening change log: %s", err)
+	}
+
+	encoder := json.NewEncoder(f)
+	for _, c := range changes {
+		err := encoder.Encode(c)
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("writing change log: %s", err)
+		}
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("writing change log: %s", err)
+	}
+	return nil
+}
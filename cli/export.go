@@ -0,0 +1,283 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/export"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"github.com/jbeshir/predictionbook-extractor/store"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func exportCommand(args []string, cfg *config) error {
+	flags := newFlagSet("export")
+	cfg.addStoreFlags(flags)
+	predictionsPath := flags.String("predictions", "", "Export all stored predictions in CSV format to the given file")
+	responsesPath := flags.String("responses", "", "Export all stored prediction responses in CSV format to the given file")
+	feeds := flags.String("feeds", "", "Write Atom and RSS feeds of recently created and judged predictions, and per-user activity, to the given directory")
+	asOf := flags.String("asof", "", "Export the dataset as the site showed it at the given RFC 3339 time or date, from the stored history")
+	knownAt := flags.String("knownat", "", "With -asof, only use history observed by crawls up to the given RFC 3339 time or date")
+	exportOptions := addExportFlags(flags)
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	csv, err := exportOptions.parse()
+	if err != nil {
+		return err
+	}
+	var shown, known time.Time
+	if *asOf != "" {
+		shown, err = parseTimeArg(*asOf)
+		if err != nil {
+			return usageError{err: fmt.Errorf("invalid -asof: %s", err)}
+		}
+	}
+	if *knownAt != "" {
+		if *asOf == "" {
+			return usageError{err: errors.New("-knownat requires -asof")}
+		}
+		known, err = parseTimeArg(*knownAt)
+		if err != nil {
+			return usageError{err: fmt.Errorf("invalid -knownat: %s", err)}
+		}
+	}
+	if *predictionsPath == "" && *responsesPath == "" && *feeds == "" {
+		return usageError{err: errors.New("at least one of -predictions, -responses or -feeds is required")}
+	}
+
+	s := store.NewStore(cfg.StoreDir)
+	dataset, err := s.Load()
+	if err != nil {
+		return fmt.Errorf("loading dataset from store: %s", err)
+	}
+	if !shown.IsZero() {
+		history, err := s.LoadHistory()
+		if err != nil {
+			return fmt.Errorf("loading history from store: %s", err)
+		}
+		dataset = store.DatasetAsOf(dataset, history, shown, known)
+	}
+
+	err = writePredictionsCsv(cfg.outputPath(*predictionsPath), dataset.Predictions, csv.predictions)
+	if err != nil {
+		return err
+	}
+	err = writeResponsesCsv(cfg.outputPath(*responsesPath), dataset.Responses, csv.responses)
+	if err != nil {
+		return err
+	}
+	err = writeDataPackage(cfg.outputPath(csv.dataPackage), csv, dataset, cfg.outputPath(*predictionsPath), cfg.outputPath(*responsesPath), "")
+	if err != nil {
+		return err
+	}
+	if *feeds != "" {
+		err = writeFeeds(cfg.outputPath(*feeds), dataset.BaseUrl, dataset.Crawled, dataset.Predictions, dataset.Responses)
+		if err != nil {
+			return fmt.Errorf("writing feeds: %s", err)
+		}
+	}
+	return nil
+}
+
+// exportFlags are the options shared by every CSV export.
+type exportFlags struct {
+	header            *bool
+	timeFormat        *string
+	outcomeFormat     *string
+	nan               *string
+	predictionColumns *string
+	responseColumns   *string
+	findingColumns    *string
+	dataPackage       *string
+}
+
+// csvOptions are the parsed export options for each kind of CSV.
+type csvOptions struct {
+	predictions *export.Options
+	responses   *export.Options
+	findings    *export.Options
+	dataPackage string
+}
+
+func addExportFlags(flags *flag.FlagSet) *exportFlags {
+	return &exportFlags{
+		header:            flags.Bool("header", false, "Write a header row naming the columns of CSV exports"),
+		timeFormat:        flags.String("timeformat", "unix", "Format of times in CSV exports: unix, rfc3339 or date"),
+		outcomeFormat:     flags.String("outcomeformat", "code", "Format of outcomes in CSV exports: code (0 unknown, 1 right, 2 wrong) or label"),
+		nan:               flags.String("nan", "NaN", "Value written in CSV exports for unknown confidences"),
+		predictionColumns: flags.String("predictioncolumns", "", "Comma-separated columns to write to predictions CSV exports, in order, from: "+strings.Join(export.PredictionColumns, ", ")),
+		responseColumns:   flags.String("responsecolumns", "", "Comma-separated columns to write to responses CSV exports, in order, from: "+strings.Join(export.ResponseColumns, ", ")),
+		findingColumns:    flags.String("findingcolumns", "", "Comma-separated columns to write to findings CSV exports, in order, from: "+strings.Join(export.FindingColumns, ", ")),
+		dataPackage:       flags.String("datapackage", "", "Write a Frictionless Data datapackage.json describing the CSV exports to the given file, which must be in a directory containing them"),
+	}
+}
+
+// parse checks the export flags, returning a usage error if any are invalid.
+func (e *exportFlags) parse() (*csvOptions, error) {
+	base := export.DefaultOptions()
+	base.Header = *e.header
+	base.NaN = *e.nan
+
+	var err error
+	base.TimeFormat, err = export.ParseTimeFormat(*e.timeFormat)
+	if err != nil {
+		return nil, usageError{err: err}
+	}
+	base.OutcomeFormat, err = export.ParseOutcomeFormat(*e.outcomeFormat)
+	if err != nil {
+		return nil, usageError{err: err}
+	}
+
+	withColumns := func(columns string, available []string) (*export.Options, error) {
+		o := *base
+		o.Columns = export.ParseColumns(columns)
+		err := export.CheckColumns(o.Columns, available)
+		if err != nil {
+			return nil, usageError{err: err}
+		}
+		return &o, nil
+	}
+	options := &csvOptions{dataPackage: *e.dataPackage}
+	options.predictions, err = withColumns(*e.predictionColumns, export.PredictionColumns)
+	if err != nil {
+		return nil, err
+	}
+	options.responses, err = withColumns(*e.responseColumns, export.ResponseColumns)
+	if err != nil {
+		return nil, err
+	}
+	options.findings, err = withColumns(*e.findingColumns, export.FindingColumns)
+	if err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// writeCsv writes to the file at path, doing nothing if path is empty.
+// What is used in errors to describe the records.
+func writeCsv(path, what string, write func(w io.Writer) error) error {
+	if path == "" {
+		return nil
+	}
+
+	f, err := createOutput(path)
+	if err != nil {
+		return fmt.Errorf("opening %s export file: %s", what, err)
+	}
+
+	err = write(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %s", what, err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("writing %s: %s", what, err)
+	}
+	return nil
+}
+
+func writePredictionsCsv(path string, ps []*predictions.PredictionSummary, o *export.Options) error {
+	return writeCsv(path, "predictions", func(w io.Writer) error {
+		return export.WritePredictions(w, ps, o)
+	})
+}
+
+func writeResponsesCsv(path string, responses []*predictions.PredictionResponse, o *export.Options) error {
+	return writeCsv(path, "prediction responses", func(w io.Writer) error {
+		return export.WriteResponses(w, responses, o)
+	})
+}
+
+func writeFindingsCsv(path string, findings []*predictions.DataQualityFinding, o *export.Options) error {
+	return writeCsv(path, "findings", func(w io.Writer) error {
+		return export.WriteFindings(w, findings, o)
+	})
+}
+
+// writeDataPackage writes a data package to path describing the CSV exports at the given
+// paths, any of which may be empty, doing nothing if path is empty.
+func writeDataPackage(path string, options *csvOptions, dataset *store.Dataset, predictionsPath, responsesPath, findingsPath string) error {
+	if path == "" {
+		return nil
+	}
+	if predictionsPath == "" && responsesPath == "" && findingsPath == "" {
+		return errors.New("writing data package: no CSV exports to describe")
+	}
+
+	pkg := export.NewDataPackage(dataset.BaseUrl, dataset.Crawled, "predictionbook-extractor", toolVersion())
+	for _, resource := range []struct {
+		path string
+		add  func(path string, o *export.Options) error
+		o    *export.Options
+	}{
+		{predictionsPath, pkg.AddPredictions, options.predictions},
+		{responsesPath, pkg.AddResponses, options.responses},
+		{findingsPath, pkg.AddFindings, options.findings},
+	} {
+		if resource.path == "" {
+			continue
+		}
+		relative, err := relativePath(filepath.Dir(path), resource.path)
+		if err != nil {
+			return fmt.Errorf("writing data package: %s", err)
+		}
+		err = resource.add(relative, resource.o)
+		if err != nil {
+			return fmt.Errorf("writing data package: %s", err)
+		}
+	}
+
+	f, err := createOutput(path)
+	if err != nil {
+		return fmt.Errorf("opening data package file: %s", err)
+	}
+	err = pkg.Write(f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("writing data package: %s", err)
+	}
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("writing data package: %s", err)
+	}
+	return nil
+}
+
+// relativePath returns target's path relative to the directory dir.
+func relativePath(dir, target string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Rel(absDir, absTarget)
+}
+
+// parseTimeArg parses an RFC 3339 time, or a date meaning midnight UTC at its start.
+func parseTimeArg(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// createOutput creates a file, along with any missing parent directories.
+func createOutput(path string) (*os.File, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return nil, err
+	}
+	return os.Create(path)
+}
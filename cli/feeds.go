@@ -0,0 +1,76 @@
+package main
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/feed"
+	"github.com/jbeshir/predictionbook-extractor/mirror"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const feedEntryLimit = 50
+
+func writeFeeds(dir, baseUrl string, crawled time.Time, ps []*predictions.PredictionSummary, responses []*predictions.PredictionResponse) error {
+	err := os.MkdirAll(filepath.Join(dir, "users"), 0755)
+	if err != nil {
+		return err
+	}
+
+	err = writeFeed(filepath.Join(dir, "created"), feed.CreatedFeed(baseUrl, crawled, ps, feedEntryLimit))
+	if err != nil {
+		return err
+	}
+
+	err = writeFeed(filepath.Join(dir, "judged"), feed.JudgedFeed(baseUrl, crawled, ps, feedEntryLimit))
+	if err != nil {
+		return err
+	}
+
+	slugs := make(map[string]bool)
+	for _, p := range ps {
+		slugs[p.CreatorSlug] = true
+	}
+	for _, r := range responses {
+		slugs[r.UserSlug] = true
+	}
+	for slug := range slugs {
+		// Slugs are scraped from links, so may not be safe to use as file names
+		if !mirror.ValidSlug(slug) {
+			continue
+		}
+
+		err = writeFeed(filepath.Join(dir, "users", slug), feed.UserFeed(baseUrl, crawled, slug, ps, responses, feedEntryLimit))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFeed writes both an Atom and RSS version of the feed, adding the extension to path.
+func writeFeed(path string, f *feed.Feed) error {
+	err := writeFeedFile(path+".atom", f, feed.WriteAtom)
+	if err != nil {
+		return err
+	}
+
+	return writeFeedFile(path+".rss", f, feed.WriteRss)
+}
+
+func writeFeedFile(path string, f *feed.Feed, write func(w io.Writer, f *feed.Feed) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = write(file, f)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFeedsSkipsUnsafeSlugs(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "feeds")
+	if err != nil {
+		t.Fatalf("Couldn't create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	feedDir := filepath.Join(dir, "feeds")
+
+	ps := []*predictions.PredictionSummary{
+		{Id: 1, Title: "Safe", CreatorSlug: "alice", Created: time.Unix(1000, 0)},
+		{Id: 2, Title: "Parent", CreatorSlug: "..", Created: time.Unix(2000, 0)},
+		{Id: 3, Title: "Nested", CreatorSlug: "a/b", Created: time.Unix(3000, 0)},
+	}
+	err = writeFeeds(feedDir, "https://example.org", time.Unix(4000, 0), ps, nil)
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+
+	_, err = os.Stat(filepath.Join(feedDir, "users", "alice.atom"))
+	if err != nil {
+		t.Errorf("Feed for safe slug should have been written: %s", err)
+	}
+	for _, path := range []string{filepath.Join(feedDir, "users.atom"), filepath.Join(feedDir, "users", "a_b.atom")} {
+		if _, err := os.Stat(path); err == nil {
+			t.Errorf("Feed for unsafe slug should not have been written to %s", path)
+		}
+	}
+}
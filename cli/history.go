@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/store"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+func historyCommand(args []string, cfg *config) error {
+	flags := newFlagSet("history")
+	cfg.addStoreFlags(flags)
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError{err: errors.New("history takes one prediction id")}
+	}
+	id, err := strconv.ParseInt(flags.Arg(0), 10, 64)
+	if err != nil {
+		return usageError{err: fmt.Errorf("invalid prediction id %s", flags.Arg(0))}
+	}
+
+	history, err := store.NewStore(cfg.StoreDir).LoadHistory()
+	if err != nil {
+		return fmt.Errorf("loading history from store: %s", err)
+	}
+	ph := history.Prediction(id)
+	if ph == nil {
+		return fmt.Errorf("prediction %d has no stored history", id)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Field\tValid from\tObserved at\tValue\n")
+	for _, v := range ph.Versions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.Field, v.ValidFrom.Format(time.RFC3339), v.ObservedAt.Format(time.RFC3339), v.Value)
+	}
+	return w.Flush()
+}
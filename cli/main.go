@@ -1,113 +1,144 @@
 package main
 
 import (
-	"context"
-	"encoding/csv"
 	"flag"
 	"fmt"
-	"github.com/jbeshir/predictionbook-extractor/htmlfetcher"
-	"github.com/jbeshir/predictionbook-extractor/predictions"
-	"golang.org/x/time/rate"
 	"os"
-	"strconv"
+	"runtime/debug"
 )
 
+// Exit codes
+const (
+	exitOk      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// version is reported in data packages, and may be set at build time with
+// -ldflags "-X main.version=...". Otherwise the module version is used, if known.
+var version = ""
+
+func toolVersion() string {
+	if version != "" {
+		return version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "devel"
+}
+
+type command struct {
+	name        string
+	description string
+	run         func(args []string, cfg *config) error
+}
+
+// usageError is returned by commands given invalid flags or arguments.
+// Printed is set if the flag package has already reported it.
+type usageError struct {
+	err     error
+	printed bool
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func commands() []*command {
+	return []*command{
+		{"crawl", "Retrieve predictions and responses, saving them to the local store and exporting them", crawlCommand},
+		{"watch", "Poll for new predictions, estimates and judgements, printing them as JSON lines", watchCommand},
+		{"export", "Export the stored predictions and responses as CSV or feeds", exportCommand},
+		{"stats", "Summarise the stored dataset", statsCommand},
+		{"score", "Score users' stored wagers by Brier score and calibration", scoreCommand},
+		{"verify", "Check the stored dataset for inconsistencies", verifyCommand},
+		{"history", "Show every stored value of a prediction's title, deadline, outcome and wagers", historyCommand},
+		{"diff", "Compare two datasets, summarising and logging what changed between them", diffCommand},
+		{"serve", "Serve the stored dataset through a read-only JSON HTTP API", serveCommand},
+		{"mirror", "Generate a static HTML mirror of the stored dataset", mirrorCommand},
+		{"search", "Search stored prediction titles and comments", searchCommand},
+		{"traintopics", "Train a topic classifier model from labelled samples", trainTopicsCommand},
+	}
+}
+
 func main() {
+	os.Exit(run())
+}
 
-	url := flag.String("url", "https://predictionbook.com", "URL of PredictionBook instance to extract from")
-	export := flag.String("export", "", "Export all predictions made in CSV format to the given file")
-	exportResponses := flag.String("exportresponses", "", "Export all prediction responses in CSV format to the given file")
+func run() int {
+	configPath := flag.String("config", os.Getenv(envPrefix+"CONFIG"), "File of flat key/value settings in YAML or TOML syntax to read shared settings from")
+	flag.Usage = usage
 	flag.Parse()
 
-	fetcher := htmlfetcher.NewFetcher(rate.NewLimiter(1, 2), 2)
-	source := predictions.NewSource(fetcher, *url)
+	if flag.NArg() == 0 {
+		usage()
+		return exitUsage
+	}
 
-	if *export != "" || *exportResponses != "" {
-		ps, err := source.AllPredictions(context.Background())
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error retrieving predictions: %s\n", err)
-			return
+	var cmd *command
+	for _, c := range commands() {
+		if c.name == flag.Arg(0) {
+			cmd = c
 		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Unknown command %s\n", flag.Arg(0))
+		usage()
+		return exitUsage
+	}
 
-		if *export != "" {
-			exportFile, err := os.Create(*export)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error opening export file: %s\n", err)
-				return
-			}
-
-			csvWriter := csv.NewWriter(exportFile)
-			for _, p := range ps {
-				err := csvWriter.Write([]string{
-					strconv.FormatInt(p.Id, 10),
-					strconv.FormatInt(p.Created.Unix(), 10),
-					strconv.FormatInt(p.Deadline.Unix(), 10),
-					strconv.FormatFloat(p.MeanConfidence, 'f', -1, 64),
-					strconv.FormatInt(p.WagerCount, 10),
-					strconv.FormatInt(int64(p.Outcome), 10),
-					p.Creator,
-					p.Title,
-				})
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error writing predictions: %s\n", err)
-					return
-				}
-			}
-
-			csvWriter.Flush()
-			err = csvWriter.Error()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing predictions: %s\n", err)
-				return
-			}
-
-			err = exportFile.Close()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing predictions: %s\n", err)
-				return
-			}
+	cfg := defaultConfig()
+	if *configPath != "" {
+		err := cfg.loadFile(*configPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %s\n", err)
+			return exitUsage
 		}
+	}
+	err := cfg.loadEnv(os.Getenv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config from environment: %s\n", err)
+		return exitUsage
+	}
 
-		if *exportResponses != "" {
-			_, responses, err := source.AllPredictionResponses(context.Background(), ps)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error retrieving prediction responses %s\n", err)
-				return
-			}
-
-			responseFile, err := os.Create(*exportResponses)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error opening response export file: %s\n", err)
-				return
-			}
-
-			csvWriter := csv.NewWriter(responseFile)
-			for _, r := range responses {
-				err := csvWriter.Write([]string{
-					strconv.FormatInt(r.Prediction, 10),
-					strconv.FormatInt(r.Time.Unix(), 10),
-					strconv.FormatFloat(r.Confidence, 'f', -1, 64),
-					r.User,
-					r.Comment,
-				})
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error writing prediction responses: %s\n", err)
-					return
-				}
-			}
-
-			csvWriter.Flush()
-			err = csvWriter.Error()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing prediction responses: %s\n", err)
-				return
-			}
-
-			err = responseFile.Close()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing prediction responses: %s\n", err)
-				return
-			}
+	err = cmd.run(flag.Args()[1:], cfg)
+	switch err := err.(type) {
+	case nil:
+		return exitOk
+	case usageError:
+		if err.err == flag.ErrHelp {
+			return exitOk
+		}
+		if !err.printed {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
 		}
+		return exitUsage
+	default:
+		fmt.Fprintf(os.Stderr, "Error %s\n", err)
+		return exitFailure
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [-config file] <command> [flags]\n\nCommands:\n", os.Args[0])
+	for _, c := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.description)
+	}
+	fmt.Fprintf(os.Stderr, "\nShared settings are read from the config file and %s* environment variables.\n\nFlags:\n", envPrefix)
+	flag.PrintDefaults()
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ContinueOnError)
+}
+
+// parseFlags parses a command's flags, returning a usage error if they are invalid.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	err := flags.Parse(args)
+	if err != nil {
+		return usageError{err: err, printed: true}
 	}
+	return nil
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/metrics"
+	"net"
+	"net/http"
+	"os"
+)
+
+// addMetricsFlags registers flags overriding where crawl metrics are exposed.
+func (c *config) addMetricsFlags(flags *flag.FlagSet) {
+	flags.StringVar(&c.MetricsAddr, "metricsaddr", c.MetricsAddr, "Address to serve Prometheus metrics on at /metrics while running, e.g. :9090")
+	flags.StringVar(&c.MetricsFile, "metricsfile", c.MetricsFile, "File to write Prometheus metrics to on exit, for node_exporter's textfile collector; should end in .prom")
+}
+
+// startMetrics begins collecting metrics if they are to be served or written, serving
+// them if configured. The returned function stops serving them and writes the file,
+// and should be deferred so metrics of failed runs are written too.
+func (c *config) startMetrics() (finish func(), err error) {
+	if c.MetricsAddr == "" && c.MetricsFile == "" {
+		return func() {}, nil
+	}
+	c.registry = metrics.NewRegistry()
+
+	var server *http.Server
+	if c.MetricsAddr != "" {
+		listener, err := net.Listen("tcp", c.MetricsAddr)
+		if err != nil {
+			return nil, fmt.Errorf("serving metrics: %s", err)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", c.registry)
+		server = &http.Server{Handler: mux}
+		go server.Serve(listener)
+	}
+
+	metricsFile := c.outputPath(c.MetricsFile)
+	return func() {
+		if server != nil {
+			server.Close()
+		}
+		if metricsFile != "" {
+			err := c.registry.WriteFile(metricsFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing metrics: %s\n", err)
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/mirror"
+	"github.com/jbeshir/predictionbook-extractor/store"
+)
+
+func mirrorCommand(args []string, cfg *config) error {
+	flags := newFlagSet("mirror")
+	cfg.addStoreFlags(flags)
+	out := flags.String("out", "mirror", "Directory to write the static HTML mirror to")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	dataset, err := store.NewStore(cfg.StoreDir).Load()
+	if err != nil {
+		return fmt.Errorf("loading dataset from store: %s", err)
+	}
+
+	err = mirror.Generate(cfg.outputPath(*out), dataset)
+	if err != nil {
+		return fmt.Errorf("generating mirror: %s", err)
+	}
+	return nil
+}
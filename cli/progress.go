@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ttyRefreshInterval limits how often the progress line is redrawn on a terminal.
+const ttyRefreshInterval = 250 * time.Millisecond
+
+// progressDisplay shows crawl progress as a continually redrawn line on a terminal, or
+// otherwise as log lines at a fixed interval and whenever a stage finishes.
+type progressDisplay struct {
+	w        io.Writer
+	tty      bool
+	interval time.Duration
+
+	last      time.Time
+	lastStage predictions.ProgressStage
+	drawn     bool
+}
+
+func newProgressDisplay(f *os.File, logInterval time.Duration) *progressDisplay {
+	d := &progressDisplay{
+		w:        f,
+		interval: logInterval,
+	}
+	info, err := f.Stat()
+	if err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		d.tty = true
+		d.interval = ttyRefreshInterval
+	}
+	return d
+}
+
+func (d *progressDisplay) report(p predictions.Progress) {
+	now := time.Now()
+	stageChanged := d.drawn && p.Stage != d.lastStage
+	finished := p.PagesTotal > 0 && p.PagesDone >= p.PagesTotal
+	if !stageChanged && !finished && now.Sub(d.last) < d.interval {
+		return
+	}
+
+	if d.tty {
+		if stageChanged {
+			fmt.Fprintln(d.w)
+		}
+		fmt.Fprintf(d.w, "\r\033[K%s", formatProgress(p))
+	} else {
+		fmt.Fprintf(d.w, "%s %s\n", now.Format(time.RFC3339), formatProgress(p))
+	}
+	d.last, d.lastStage, d.drawn = now, p.Stage, true
+}
+
+// finish ends the progress line on a terminal, so later output starts on a new line.
+func (d *progressDisplay) finish() {
+	if d.tty && d.drawn {
+		fmt.Fprintln(d.w)
+	}
+	d.drawn = false
+}
+
+func formatProgress(p predictions.Progress) string {
+	stage := p.Stage.String()
+	parts := []string{strings.ToUpper(stage[:1]) + stage[1:] + ":"}
+	if p.PagesTotal > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d pages (%d%%),", p.PagesDone, p.PagesTotal, p.PagesDone*100/p.PagesTotal))
+	} else {
+		parts = append(parts, fmt.Sprintf("%d pages,", p.PagesDone))
+	}
+	if p.Stage == predictions.RetrievingResponses {
+		parts = append(parts, fmt.Sprintf("%d responses,", p.Responses))
+	} else {
+		parts = append(parts, fmt.Sprintf("%d predictions,", p.Predictions))
+	}
+	parts = append(parts, fmt.Sprintf("%d errors, %.1f pages/s", p.Errors, p.Rate))
+	if remaining := p.Remaining(); remaining > 0 {
+		parts = append(parts, "- ETA "+remaining.Round(time.Second).String())
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"github.com/jbeshir/predictionbook-extractor/search"
+	"github.com/jbeshir/predictionbook-extractor/store"
+	"strings"
+	"time"
+)
+
+func searchCommand(args []string, cfg *config) error {
+	flags := newFlagSet("search")
+	cfg.addStoreFlags(flags)
+	creator := flags.String("creator", "", "Only return predictions created by the user with the given slug or name")
+	outcome := flags.String("outcome", "", "Only return predictions with the given outcome: right, wrong or unknown")
+	after := flags.String("after", "", "Only return predictions created on or after the given date, as YYYY-MM-DD")
+	before := flags.String("before", "", "Only return predictions created before the given date, as YYYY-MM-DD")
+	limit := flags.Int("limit", 20, "Maximum number of results to print; 0 prints all")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if *outcome != "" {
+		var o predictions.Outcome
+		err := o.UnmarshalText([]byte(*outcome))
+		if err != nil {
+			return usageError{err: err}
+		}
+	}
+
+	filter := &predictions.Filter{
+		Creator: *creator,
+		Outcome: *outcome,
+	}
+	for _, date := range []struct {
+		value string
+		t     *time.Time
+	}{
+		{*after, &filter.CreatedAfter},
+		{*before, &filter.CreatedBefore},
+	} {
+		if date.value == "" {
+			continue
+		}
+		t, err := time.Parse("2006-01-02", date.value)
+		if err != nil {
+			return usageError{err: fmt.Errorf("invalid date %s: %s", date.value, err)}
+		}
+		*date.t = t
+	}
+
+	dataset, err := store.NewStore(cfg.StoreDir).Load()
+	if err != nil {
+		return fmt.Errorf("loading dataset from store: %s", err)
+	}
+
+	results, err := search.NewIndex(dataset.Predictions, dataset.Responses).Search(strings.Join(flags.Args(), " "), filter)
+	if err != nil {
+		return usageError{err: fmt.Errorf("invalid query: %s", err)}
+	}
+
+	for i, r := range results {
+		if *limit > 0 && i >= *limit {
+			break
+		}
+
+		p := r.Prediction
+		fmt.Printf("%d\t%.3f\t%s\t%s\t%s\n", p.Id, r.Score, p.Outcome, p.Created.Format("2006-01-02"), p.Title)
+		for _, response := range r.Responses {
+			fmt.Printf("\t%s: %s\n", response.User, response.Comment)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestSearchRejectsInvalidOutcome(t *testing.T) {
+	t.Parallel()
+
+	cfg := defaultConfig()
+	cfg.StoreDir = "testdata/does-not-exist"
+	err := searchCommand([]string{"-outcome", "yes", "query"}, cfg)
+	if _, ok := err.(usageError); !ok {
+		t.Errorf("Expected usage error for an invalid outcome, was %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/server"
+	"github.com/jbeshir/predictionbook-extractor/store"
+	"net/http"
+)
+
+func serveCommand(args []string, cfg *config) error {
+	flags := newFlagSet("serve")
+	cfg.addStoreFlags(flags)
+	addr := flags.String("addr", "localhost:8080", "Address to listen for HTTP requests on")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	dataset, err := store.NewStore(cfg.StoreDir).Load()
+	if err != nil {
+		return fmt.Errorf("loading dataset from store: %s", err)
+	}
+
+	err = http.ListenAndServe(*addr, server.NewServer(dataset))
+	if err != nil {
+		return fmt.Errorf("serving HTTP API: %s", err)
+	}
+	return nil
+}
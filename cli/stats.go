@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"github.com/jbeshir/predictionbook-extractor/scoring"
+	"github.com/jbeshir/predictionbook-extractor/store"
+	"math"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+func statsCommand(args []string, cfg *config) error {
+	flags := newFlagSet("stats")
+	cfg.addStoreFlags(flags)
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	dataset, err := store.NewStore(cfg.StoreDir).Load()
+	if err != nil {
+		return fmt.Errorf("loading dataset from store: %s", err)
+	}
+
+	outcomes := make(map[predictions.Outcome]int)
+	var first, last time.Time
+	for _, p := range dataset.Predictions {
+		outcomes[p.Outcome]++
+		if first.IsZero() || p.Created.Before(first) {
+			first = p.Created
+		}
+		if p.Created.After(last) {
+			last = p.Created
+		}
+	}
+
+	kinds := make(map[predictions.ResponseKind]int)
+	for _, r := range dataset.Responses {
+		kinds[r.Kind]++
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Source:\t%s\n", dataset.BaseUrl)
+	fmt.Fprintf(w, "Crawled:\t%s\n", dataset.Crawled.Format(time.RFC3339))
+	fmt.Fprintf(w, "Predictions:\t%d (%d right, %d wrong, %d unknown)\n", len(dataset.Predictions), outcomes[predictions.Right], outcomes[predictions.Wrong], outcomes[predictions.Unknown])
+	if len(dataset.Predictions) > 0 {
+		fmt.Fprintf(w, "Created:\t%s to %s\n", first.Format("2006-01-02"), last.Format("2006-01-02"))
+	}
+	fmt.Fprintf(w, "Responses:\t%d (%d wagers, %d comments, %d both)\n", len(dataset.Responses), kinds[predictions.WagerOnly], kinds[predictions.CommentOnly], kinds[predictions.WagerAndComment])
+	fmt.Fprintf(w, "Users:\t%d\n", len(scoring.UserSummaries(dataset.Predictions, dataset.Responses)))
+	return w.Flush()
+}
+
+func scoreCommand(args []string, cfg *config) error {
+	flags := newFlagSet("score")
+	cfg.addStoreFlags(flags)
+	user := flags.String("user", "", "Only score the user with the given slug, including their calibration table")
+	minJudged := flags.Int64("minjudged", 1, "Only list users with at least the given number of judged wagers")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	dataset, err := store.NewStore(cfg.StoreDir).Load()
+	if err != nil {
+		return fmt.Errorf("loading dataset from store: %s", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Slug\tName\tPredictions\tWagers\tJudged\tBrier score\n")
+	var found *scoring.UserSummary
+	for _, u := range scoring.UserSummaries(dataset.Predictions, dataset.Responses) {
+		if *user != "" && u.Slug != *user {
+			continue
+		}
+		if *user == "" && u.JudgedWagers < *minJudged {
+			continue
+		}
+
+		found = u
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%s\n", u.Slug, u.Name, u.Predictions, u.Wagers, u.JudgedWagers, formatScore(u.BrierScore))
+	}
+
+	if *user != "" {
+		if found == nil {
+			return fmt.Errorf("finding user: no predictions or wagers by %s in the store", *user)
+		}
+
+		fmt.Fprintf(w, "\nConfidence\tWagers\tRight\tAccuracy\n")
+		for _, b := range found.Calibration {
+			fmt.Fprintf(w, "%.0f%%\t%d\t%d\t%s\n", b.Confidence*100, b.Count, b.Right, formatScore(b.Accuracy))
+		}
+	}
+	return w.Flush()
+}
+
+func formatScore(f float64) string {
+	if math.IsNaN(f) {
+		return "-"
+	}
+	return fmt.Sprintf("%.3f", f)
+}
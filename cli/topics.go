@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/classify"
+	"os"
+)
+
+func trainTopicsCommand(args []string, cfg *config) error {
+	flags := newFlagSet("traintopics")
+	cfg.addStoreFlags(flags)
+	samplesPath := flags.String("samples", "", "CSV file of labelled samples, with a topic and text on each row")
+	out := flags.String("out", "topics.json", "File to write the trained classifier model to")
+	minSimilarity := flags.Float64("minsimilarity", classify.DefaultMinSimilarity, "Similarity to the nearest topic below which no topic is assigned")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if *samplesPath == "" {
+		return usageError{err: errors.New("-samples is required")}
+	}
+
+	samplesFile, err := os.Open(*samplesPath)
+	if err != nil {
+		return fmt.Errorf("opening samples file: %s", err)
+	}
+	samples, err := classify.ReadSamples(samplesFile)
+	samplesFile.Close()
+	if err != nil {
+		return fmt.Errorf("reading samples: %s", err)
+	}
+
+	model, err := classify.Train(samples)
+	if err != nil {
+		return fmt.Errorf("training classifier: %s", err)
+	}
+	model.MinSimilarity = *minSimilarity
+
+	modelFile, err := createOutput(cfg.outputPath(*out))
+	if err != nil {
+		return fmt.Errorf("opening model file: %s", err)
+	}
+	err = model.Save(modelFile)
+	if err != nil {
+		modelFile.Close()
+		return fmt.Errorf("writing model: %s", err)
+	}
+	err = modelFile.Close()
+	if err != nil {
+		return fmt.Errorf("writing model: %s", err)
+	}
+	return nil
+}
+
+func loadClassifier(rulesPath, modelPath string) (*classify.Classifier, error) {
+	var rules []*classify.Rule
+	var model *classify.Model
+	var err error
+	if rulesPath != "" {
+		rules, err = classify.LoadRules(rulesPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if modelPath != "" {
+		model, err = classify.LoadModel(modelPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return classify.NewClassifier(rules, model), nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/store"
+)
+
+func verifyCommand(args []string, cfg *config) error {
+	flags := newFlagSet("verify")
+	cfg.addStoreFlags(flags)
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	dataset, err := store.NewStore(cfg.StoreDir).Load()
+	if err != nil {
+		return fmt.Errorf("loading dataset from store: %s", err)
+	}
+
+	problems := store.Verify(dataset)
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("verifying dataset: found %d problems", len(problems))
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"github.com/jbeshir/predictionbook-extractor/watch"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+func watchCommand(args []string, cfg *config) error {
+	flags := newFlagSet("watch")
+	cfg.addSourceFlags(flags)
+	cfg.addStoreFlags(flags)
+	cfg.addMetricsFlags(flags)
+	interval := flags.Duration("interval", 5*time.Minute, "Interval between polls")
+	webhooks := flags.String("webhooks", "", "Comma-separated URLs to POST events to")
+	webhookSecret := flags.String("webhooksecret", "", "Secret used to sign webhook payloads")
+	webhookDeadLetter := flags.String("webhookdeadletter", "webhook-deadletter.jsonl", "File to record webhook deliveries which failed after retrying")
+	err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	err = cfg.validate()
+	if err != nil {
+		return usageError{err: err}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	finishMetrics, err := cfg.startMetrics()
+	if err != nil {
+		return err
+	}
+	defer finishMetrics()
+
+	fetcher, err := cfg.newFetcher(ctx)
+	if err != nil {
+		return fmt.Errorf("preparing to crawl: %s", err)
+	}
+	htmlSource, ok := cfg.newSource(fetcher).(*predictions.Source)
+	if !ok {
+		return usageError{err: errors.New("watch mode is not supported through the JSON API")}
+	}
+
+	sinks := watch.MultiSink{watch.NewJsonLinesSink(os.Stdout)}
+	var webhookSink *watch.WebhookSink
+	if *webhooks != "" {
+		var endpoints []*watch.WebhookEndpoint
+		for _, webhookUrl := range strings.Split(*webhooks, ",") {
+			endpoints = append(endpoints, &watch.WebhookEndpoint{
+				Url:    strings.TrimSpace(webhookUrl),
+				Secret: *webhookSecret,
+			})
+		}
+		webhookSink = watch.NewWebhookSink(ctx, endpoints, cfg.outputPath(*webhookDeadLetter), 5, time.Second, cfg.newLogger())
+		sinks = append(sinks, webhookSink)
+	}
+
+	watcher := watch.NewWatcher(htmlSource, *interval, sinks, cfg.newLogger())
+	err = watcher.Run(ctx)
+	if webhookSink != nil {
+		closeErr := webhookSink.Close()
+		if closeErr != nil && (err == nil || err == context.Canceled) {
+			err = closeErr
+		}
+	}
+	if err != nil && err != context.Canceled {
+		return fmt.Errorf("watching for changes: %s", err)
+	}
+	return nil
+}
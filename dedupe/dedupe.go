@@ -0,0 +1,219 @@
+package dedupe
+
+import (
+	"encoding/binary"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	shingleSize = 3
+	hashCount   = 64
+
+	// candidateRecall is the least chance that a pair whose similarity is exactly the
+	// threshold shares a band, and so is checked at all.
+	candidateRecall = 0.99
+)
+
+// Detector clusters predictions whose normalised titles are similar and whose deadlines
+// are close together, as happens when a prediction is created twice or copied by another
+// user. Candidate pairs are found by MinHash locality sensitive hashing over character
+// shingles of the titles, then checked against the exact Jaccard similarity.
+type Detector struct {
+	threshold      float64
+	deadlineWindow time.Duration
+	seeds          [hashCount][2]uint64
+	bands          int
+	rowsPerBand    int
+}
+
+// NewDetector returns a detector treating predictions as near-duplicates when the Jaccard
+// similarity of their title shingles is at least threshold and their deadlines are no more
+// than deadlineWindow apart. A negative window ignores deadlines.
+func NewDetector(threshold float64, deadlineWindow time.Duration) *Detector {
+	d := &Detector{
+		threshold:      threshold,
+		deadlineWindow: deadlineWindow,
+		rowsPerBand:    bandRows(threshold),
+	}
+	d.bands = hashCount / d.rowsPerBand
+
+	// Fixed seeds keep clusters the same between runs
+	r := rand.New(rand.NewSource(1))
+	for i := range d.seeds {
+		d.seeds[i] = [2]uint64{r.Uint64() | 1, r.Uint64()}
+	}
+	return d
+}
+
+// Clusters returns each group of two or more near-duplicate predictions, ordered by id
+// within each cluster and by first id between clusters.
+func (d *Detector) Clusters(ps []*predictions.PredictionSummary) (clusters [][]*predictions.PredictionSummary) {
+	shingles := make([]map[uint64]bool, len(ps))
+	buckets := make(map[uint64][]int)
+	for i, p := range ps {
+		shingles[i] = shingleSet(normaliseTitle(p.Title))
+		if len(shingles[i]) == 0 {
+			continue
+		}
+
+		signature := d.signature(shingles[i])
+		for band := 0; band < d.bands; band++ {
+			h := fnv.New64a()
+			buf := make([]byte, 8)
+			binary.LittleEndian.PutUint64(buf, uint64(band))
+			h.Write(buf)
+			for _, v := range signature[band*d.rowsPerBand : (band+1)*d.rowsPerBand] {
+				binary.LittleEndian.PutUint64(buf, v)
+				h.Write(buf)
+			}
+			key := h.Sum64()
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	parent := make([]int, len(ps))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	checked := make(map[[2]int]bool)
+	for _, bucket := range buckets {
+		for x := 0; x < len(bucket); x++ {
+			for y := x + 1; y < len(bucket); y++ {
+				i, j := bucket[x], bucket[y]
+				if checked[[2]int{i, j}] {
+					continue
+				}
+				checked[[2]int{i, j}] = true
+
+				if find(i) != find(j) && d.duplicates(ps[i], ps[j], shingles[i], shingles[j]) {
+					parent[find(i)] = find(j)
+				}
+			}
+		}
+	}
+
+	byRoot := make(map[int][]*predictions.PredictionSummary)
+	for i, p := range ps {
+		root := find(i)
+		byRoot[root] = append(byRoot[root], p)
+	}
+	for _, cluster := range byRoot {
+		if len(cluster) < 2 {
+			continue
+		}
+		sort.Slice(cluster, func(i, j int) bool {
+			return cluster[i].Id < cluster[j].Id
+		})
+		clusters = append(clusters, cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i][0].Id < clusters[j][0].Id
+	})
+	return
+}
+
+// Assign sets the duplicate cluster of each prediction to the lowest id in its cluster,
+// or zero if it has no near-duplicates.
+func (d *Detector) Assign(ps []*predictions.PredictionSummary) {
+	for _, p := range ps {
+		p.DuplicateCluster = 0
+	}
+	for _, cluster := range d.Clusters(ps) {
+		for _, p := range cluster {
+			p.DuplicateCluster = cluster[0].Id
+		}
+	}
+}
+
+// bandRows returns the most signature rows per band, so the fewest needless candidates,
+// with which pairs at the threshold still become candidates with at least candidateRecall.
+// A pair with similarity s shares one of b bands of r rows with probability 1-(1-s^r)^b.
+func bandRows(threshold float64) int {
+	for rows := hashCount; rows > 1; rows-- {
+		bands := float64(hashCount / rows)
+		if 1-math.Pow(1-math.Pow(threshold, float64(rows)), bands) >= candidateRecall {
+			return rows
+		}
+	}
+	return 1
+}
+
+func (d *Detector) duplicates(a, b *predictions.PredictionSummary, aShingles, bShingles map[uint64]bool) bool {
+	gap := a.Deadline.Sub(b.Deadline)
+	if gap < 0 {
+		gap = -gap
+	}
+	if d.deadlineWindow >= 0 && gap > d.deadlineWindow {
+		return false
+	}
+	return jaccard(aShingles, bShingles) >= d.threshold
+}
+
+func (d *Detector) signature(shingles map[uint64]bool) (signature [hashCount]uint64) {
+	for i := range signature {
+		signature[i] = math.MaxUint64
+	}
+	for s := range shingles {
+		for i, seed := range d.seeds {
+			v := s*seed[0] + seed[1]
+			if v < signature[i] {
+				signature[i] = v
+			}
+		}
+	}
+	return
+}
+
+// normaliseTitle lowercases the title and reduces it to words of letters and digits
+// separated by single spaces.
+func normaliseTitle(title string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// shingleSet returns the hashes of every run of shingleSize characters in the text.
+// Texts shorter than that are a single shingle.
+func shingleSet(text string) map[uint64]bool {
+	set := make(map[uint64]bool)
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return set
+	}
+
+	for i := 0; i == 0 || i+shingleSize <= len(runes); i++ {
+		end := i + shingleSize
+		if end > len(runes) {
+			end = len(runes)
+		}
+		h := fnv.New64a()
+		h.Write([]byte(string(runes[i:end])))
+		set[h.Sum64()] = true
+	}
+	return set
+}
+
+func jaccard(a, b map[uint64]bool) float64 {
+	intersection := 0
+	for s := range a {
+		if b[s] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
@@ -0,0 +1,103 @@
+package dedupe
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testPredictions() []*predictions.PredictionSummary {
+	deadline := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []*predictions.PredictionSummary{
+		{Id: 1, Title: "The UK will leave the EU by 2019", Deadline: deadline},
+		{Id: 2, Title: "Bitcoin will be worth more than $10,000", Deadline: deadline},
+		{Id: 3, Title: "the UK will leave the EU by 2019.", Deadline: deadline.Add(24 * time.Hour)},
+		{Id: 4, Title: "The UK will leave the EU by 2019!!", Deadline: deadline.AddDate(1, 0, 0)},
+		{Id: 5, Title: "The UK will leave the E.U. by 2019", Deadline: deadline},
+		{Id: 6, Title: "Bitcoin will be worth more than $10,000", Deadline: deadline},
+		{Id: 7, Title: "", Deadline: deadline},
+		{Id: 8, Title: "", Deadline: deadline},
+	}
+}
+
+func TestClusters(t *testing.T) {
+	t.Parallel()
+
+	clusters := NewDetector(0.7, 7*24*time.Hour).Clusters(testPredictions())
+	expected := [][]int64{{1, 3, 5}, {2, 6}}
+	if len(clusters) != len(expected) {
+		t.Fatalf("Incorrect number of clusters; should be %d, was %d", len(expected), len(clusters))
+	}
+	for i, cluster := range clusters {
+		if len(cluster) != len(expected[i]) {
+			t.Errorf("Incorrect size of cluster %d; should be %d, was %d", i, len(expected[i]), len(cluster))
+			continue
+		}
+		for j, p := range cluster {
+			if p.Id != expected[i][j] {
+				t.Errorf("Incorrect prediction in cluster %d; should be %d, was %d", i, expected[i][j], p.Id)
+			}
+		}
+	}
+}
+
+func TestAssignIgnoringDeadlines(t *testing.T) {
+	t.Parallel()
+
+	ps := testPredictions()
+	NewDetector(0.9, -1).Assign(ps)
+
+	expected := []int64{1, 2, 1, 1, 0, 2, 0, 0}
+	for i, p := range ps {
+		if p.DuplicateCluster != expected[i] {
+			t.Errorf("Incorrect cluster for prediction %d; should be %d, was %d", p.Id, expected[i], p.DuplicateCluster)
+		}
+	}
+}
+
+func TestClustersRecallAtLowThreshold(t *testing.T) {
+	t.Parallel()
+
+	words := strings.Fields("alpha bravo charlie delta echo foxtrot golf hotel india juliet kilo lima mike november oscar papa quebec romeo sierra tango uniform victor whiskey xray yankee zulu")
+	r := rand.New(rand.NewSource(2))
+	title := func(n int) []string {
+		title := make([]string, n)
+		for i := range title {
+			title[i] = words[r.Intn(len(words))]
+		}
+		return title
+	}
+
+	// Pairs of titles sharing a few words, so their similarity is low
+	var ps []*predictions.PredictionSummary
+	for i := 0; i < 100; i++ {
+		shared := title(4)
+		a := append(title(4), shared...)
+		b := append(append([]string{}, shared...), title(4)...)
+		ps = append(ps,
+			&predictions.PredictionSummary{Id: int64(2*i + 1), Title: strings.Join(a, " ")},
+			&predictions.PredictionSummary{Id: int64(2*i + 2), Title: strings.Join(b, " ")},
+		)
+	}
+
+	threshold := 0.2
+	detector := NewDetector(threshold, -1)
+	detector.Assign(ps)
+
+	pairs := 0
+	for i := 0; i < len(ps); i += 2 {
+		a, b := ps[i], ps[i+1]
+		if jaccard(shingleSet(normaliseTitle(a.Title)), shingleSet(normaliseTitle(b.Title))) < threshold {
+			continue
+		}
+		pairs++
+		if a.DuplicateCluster == 0 || a.DuplicateCluster != b.DuplicateCluster {
+			t.Errorf("Predictions %d and %d should have been clustered", a.Id, b.Id)
+		}
+	}
+	if pairs < 50 {
+		t.Errorf("Too few pairs above the threshold to test recall; should be at least %d, was %d", 50, pairs)
+	}
+}
@@ -0,0 +1,254 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DataPackage describes exported CSV files as a Frictionless Data tabular data package,
+// so they can be validated and loaded by standard tooling.
+type DataPackage struct {
+	Profile   string      `json:"profile"`
+	Name      string      `json:"name"`
+	Title     string      `json:"title"`
+	Created   string      `json:"created"`
+	Crawled   string      `json:"crawled,omitempty"`
+	Sources   []*Source   `json:"sources,omitempty"`
+	Tool      *Tool       `json:"tool"`
+	Resources []*Resource `json:"resources"`
+}
+
+type Source struct {
+	Title string `json:"title"`
+	Path  string `json:"path"`
+}
+
+type Tool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type Resource struct {
+	Profile   string   `json:"profile"`
+	Name      string   `json:"name"`
+	Path      string   `json:"path"`
+	Format    string   `json:"format"`
+	Mediatype string   `json:"mediatype"`
+	Encoding  string   `json:"encoding"`
+	Dialect   *Dialect `json:"dialect"`
+	Schema    *Schema  `json:"schema"`
+}
+
+type Dialect struct {
+	Header bool `json:"header"`
+}
+
+type Schema struct {
+	Fields        []*Field      `json:"fields"`
+	PrimaryKey    []string      `json:"primaryKey,omitempty"`
+	ForeignKeys   []*ForeignKey `json:"foreignKeys,omitempty"`
+	MissingValues []string      `json:"missingValues"`
+}
+
+type Field struct {
+	Name        string       `json:"name"`
+	Type        string       `json:"type"`
+	Format      string       `json:"format,omitempty"`
+	Description string       `json:"description"`
+	Constraints *Constraints `json:"constraints,omitempty"`
+}
+
+type Constraints struct {
+	Required bool          `json:"required,omitempty"`
+	Minimum  interface{}   `json:"minimum,omitempty"`
+	Maximum  interface{}   `json:"maximum,omitempty"`
+	Enum     []interface{} `json:"enum,omitempty"`
+}
+
+type ForeignKey struct {
+	Fields    []string          `json:"fields"`
+	Reference *ForeignReference `json:"reference"`
+}
+
+type ForeignReference struct {
+	Resource string   `json:"resource"`
+	Fields   []string `json:"fields"`
+}
+
+// NewDataPackage describes a crawl of the instance at baseUrl made at crawled, either of
+// which may be zero if unknown, along with the name and version of the exporting tool.
+func NewDataPackage(baseUrl string, crawled time.Time, toolName, toolVersion string) *DataPackage {
+	pkg := &DataPackage{
+		Profile: "tabular-data-package",
+		Name:    "predictionbook",
+		Title:   "PredictionBook predictions",
+		Created: time.Now().UTC().Format(time.RFC3339),
+		Tool:    &Tool{Name: toolName, Version: toolVersion},
+	}
+	if !crawled.IsZero() {
+		pkg.Crawled = crawled.UTC().Format(time.RFC3339)
+	}
+	if baseUrl != "" {
+		pkg.Title = "PredictionBook predictions from " + baseUrl
+		pkg.Sources = []*Source{{Title: "PredictionBook", Path: baseUrl}}
+	}
+	return pkg
+}
+
+// AddPredictions adds a resource for a predictions CSV written with the given options.
+// Path must be relative to the directory the package will be written to.
+func (pkg *DataPackage) AddPredictions(path string, o *Options) error {
+	return pkg.addResource("predictions", path, o, PredictionColumns[:defaultPredictionColumns], []string{"id"})
+}
+
+// AddResponses adds a resource for a responses CSV written with the given options,
+// referencing the predictions resource if one has been added with an id column.
+func (pkg *DataPackage) AddResponses(path string, o *Options) error {
+	return pkg.addResource("responses", path, o, ResponseColumns[:defaultResponseColumns], nil)
+}
+
+// AddFindings adds a resource for a findings CSV written with the given options,
+// referencing the predictions resource if one has been added with an id column.
+func (pkg *DataPackage) AddFindings(path string, o *Options) error {
+	return pkg.addResource("findings", path, o, FindingColumns, nil)
+}
+
+func (pkg *DataPackage) addResource(name, path string, o *Options, defaults []string, primaryKey []string) error {
+	if filepath.IsAbs(path) || strings.HasPrefix(filepath.Clean(path), "..") {
+		return errors.New("data package resource " + path + " must be inside the package directory")
+	}
+
+	columns := o.Columns
+	if len(columns) == 0 {
+		columns = defaults
+	}
+	schema := &Schema{MissingValues: []string{""}}
+	if o.NaN != "NaN" && o.NaN != "" {
+		schema.MissingValues = append(schema.MissingValues, o.NaN)
+	}
+	for _, c := range columns {
+		schema.Fields = append(schema.Fields, fieldFor(name, c, o))
+	}
+	if len(primaryKey) > 0 && hasColumn(columns, primaryKey[0]) {
+		schema.PrimaryKey = primaryKey
+	}
+	if name != "predictions" && hasColumn(columns, "prediction") {
+		predictions := pkg.resource("predictions")
+		if predictions != nil && len(predictions.Schema.PrimaryKey) > 0 {
+			schema.ForeignKeys = []*ForeignKey{{
+				Fields:    []string{"prediction"},
+				Reference: &ForeignReference{Resource: "predictions", Fields: []string{"id"}},
+			}}
+		}
+	}
+
+	pkg.Resources = append(pkg.Resources, &Resource{
+		Profile:   "tabular-data-resource",
+		Name:      name,
+		Path:      filepath.ToSlash(filepath.Clean(path)),
+		Format:    "csv",
+		Mediatype: "text/csv",
+		Encoding:  "utf-8",
+		Dialect:   &Dialect{Header: o.Header},
+		Schema:    schema,
+	})
+	return nil
+}
+
+func (pkg *DataPackage) resource(name string) *Resource {
+	for _, r := range pkg.Resources {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// Write writes the package as indented JSON.
+func (pkg *DataPackage) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(pkg)
+}
+
+func hasColumn(columns []string, name string) bool {
+	for _, c := range columns {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+var columnDescriptions = map[string]string{
+	"predictions.id":                "PredictionBook's id for the prediction",
+	"predictions.created":           "When the prediction was made",
+	"predictions.deadline":          "When the prediction is to be judged",
+	"predictions.mean_confidence":   "Mean confidence of all wagers, from 0 to 1",
+	"predictions.wager_count":       "Number of wagers made",
+	"predictions.outcome":           "Outcome of the prediction's latest judgement",
+	"predictions.creator":           "Display name of the prediction's creator",
+	"predictions.title":             "The prediction's statement",
+	"predictions.details":           "Text of the prediction's details",
+	"predictions.details_urls":      "Space-separated URLs linked from the prediction's details",
+	"predictions.topics":            "Space-separated topics assigned by the topic classifier",
+	"predictions.duplicate_cluster": "Lowest id among the prediction's near-duplicates, if it has any",
+	"predictions.creator_slug":      "Profile slug of the prediction's creator",
+	"predictions.judged":            "When the prediction was last judged",
+	"responses.prediction":          "Id of the prediction responded to",
+	"responses.time":                "When the response was made",
+	"responses.confidence":          "Confidence wagered, from 0 to 1, or missing for comments",
+	"responses.user":                "Display name of the responding user",
+	"responses.comment":             "Text of the response's comment",
+	"responses.comment_markdown":    "The comment as Markdown",
+	"responses.urls":                "Space-separated URLs in the comment",
+	"responses.mentions":            "Space-separated users mentioned in the comment",
+	"responses.kind":                "Whether the response is a wager, comment, or both",
+	"responses.user_slug":           "Profile slug of the responding user",
+	"findings.prediction":           "Id of the prediction whose list and prediction pages disagree",
+	"findings.field":                "Field which disagrees",
+	"findings.list_value":           "Value shown on the list page",
+	"findings.page_value":           "Value shown on the prediction page",
+}
+
+func fieldFor(resource, column string, o *Options) *Field {
+	f := &Field{Name: column, Type: "string", Description: columnDescriptions[resource+"."+column]}
+	switch column {
+	case "id", "prediction":
+		f.Type = "integer"
+		f.Constraints = &Constraints{Required: true}
+	case "wager_count":
+		f.Type = "integer"
+		f.Constraints = &Constraints{Minimum: 0}
+	case "duplicate_cluster":
+		f.Type = "integer"
+	case "mean_confidence", "confidence":
+		f.Type = "number"
+		f.Constraints = &Constraints{Minimum: 0, Maximum: 1}
+	case "created", "deadline", "judged", "time":
+		switch o.TimeFormat {
+		case RFC3339Time:
+			f.Type = "datetime"
+		case DateOnly:
+			f.Type = "date"
+		default:
+			f.Type = "integer"
+			f.Description += ", in Unix seconds"
+		}
+	case "outcome":
+		if o.OutcomeFormat == OutcomeLabel {
+			f.Constraints = &Constraints{Enum: []interface{}{"unknown", "right", "wrong"}}
+		} else {
+			f.Type = "integer"
+			f.Description += ": 0 unknown, 1 right, 2 wrong"
+			f.Constraints = &Constraints{Enum: []interface{}{0, 1, 2}}
+		}
+	case "kind":
+		f.Constraints = &Constraints{Enum: []interface{}{"unknown", "wager", "comment", "wager_and_comment"}}
+	}
+	return f
+}
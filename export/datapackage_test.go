@@ -0,0 +1,63 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDataPackage(t *testing.T) {
+	t.Parallel()
+
+	pkg := NewDataPackage("https://example.org", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), "predictionbook-extractor", "v1.2.3")
+	err := pkg.AddPredictions("predictions.csv", &Options{Header: true, TimeFormat: RFC3339Time, OutcomeFormat: OutcomeLabel, NaN: "NA"})
+	if err != nil {
+		t.Fatalf("Error adding predictions should have been nil, was %s", err)
+	}
+	err = pkg.AddResponses("csv/responses.csv", &Options{Columns: []string{"prediction", "time", "confidence"}, NaN: "NaN"})
+	if err != nil {
+		t.Fatalf("Error adding responses should have been nil, was %s", err)
+	}
+	err = pkg.AddFindings("../findings.csv", DefaultOptions())
+	if err == nil {
+		t.Errorf("Error should have been returned adding a resource outside the package directory")
+	}
+
+	var b bytes.Buffer
+	err = pkg.Write(&b)
+	if err != nil {
+		t.Fatalf("Error writing should have been nil, was %s", err)
+	}
+	var decoded DataPackage
+	err = json.Unmarshal(b.Bytes(), &decoded)
+	if err != nil {
+		t.Fatalf("Error decoding should have been nil, was %s", err)
+	}
+
+	if decoded.Profile != "tabular-data-package" || decoded.Crawled != "2020-01-02T00:00:00Z" || decoded.Tool.Version != "v1.2.3" || len(decoded.Sources) != 1 || decoded.Sources[0].Path != "https://example.org" {
+		t.Errorf("Incorrect package metadata, was %s", b.String())
+	}
+	if len(decoded.Resources) != 2 {
+		t.Fatalf("Incorrect number of resources; should be 2, was %d", len(decoded.Resources))
+	}
+
+	predictions := decoded.Resources[0].Schema
+	if !decoded.Resources[0].Dialect.Header || len(predictions.PrimaryKey) != 1 || predictions.PrimaryKey[0] != "id" {
+		t.Errorf("Incorrect predictions resource, was %+v", decoded.Resources[0])
+	}
+	if len(predictions.Fields) != 8 || predictions.Fields[1].Name != "created" || predictions.Fields[1].Type != "datetime" || predictions.Fields[5].Type != "string" {
+		t.Errorf("Incorrect predictions fields, were %s", b.String())
+	}
+	if len(predictions.MissingValues) != 2 || predictions.MissingValues[1] != "NA" {
+		t.Errorf("Incorrect missing values; should include NA, were %v", predictions.MissingValues)
+	}
+
+	responses := decoded.Resources[1]
+	if responses.Path != "csv/responses.csv" || responses.Dialect.Header || len(responses.Schema.Fields) != 3 || responses.Schema.Fields[1].Type != "integer" {
+		t.Errorf("Incorrect responses resource, was %+v", responses)
+	}
+	if len(responses.Schema.ForeignKeys) != 1 || responses.Schema.ForeignKeys[0].Reference.Resource != "predictions" || responses.Schema.ForeignKeys[0].Reference.Fields[0] != "id" {
+		t.Errorf("Incorrect responses foreign keys, were %+v", responses.Schema.ForeignKeys)
+	}
+}
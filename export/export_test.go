@@ -0,0 +1,195 @@
+package export
+
+import (
+	"bytes"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"math"
+	"testing"
+	"time"
+)
+
+var testPredictions = []*predictions.PredictionSummary{
+	{
+		Id:             7,
+		Title:          "Title, with comma",
+		Creator:        "Alice",
+		CreatorSlug:    "alice",
+		Created:        time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Deadline:       time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+		MeanConfidence: math.NaN(),
+		Outcome:        predictions.Wrong,
+		Topics:         []string{"politics", "economics"},
+	},
+}
+
+func TestWritePredictionsDefault(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	err := WritePredictions(&b, testPredictions, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+
+	// The default columns are those of the first exports
+	expected := "7,1577934245,1609545600,NaN,0,2,Alice,\"Title, with comma\"\n"
+	if b.String() != expected {
+		t.Errorf("Incorrect output; should be %q, was %q", expected, b.String())
+	}
+}
+
+func TestWriteResponsesDefault(t *testing.T) {
+	t.Parallel()
+
+	responses := []*predictions.PredictionResponse{
+		{Prediction: 7, Time: time.Unix(100, 0), Confidence: 0.25, User: "Bob", UserSlug: "bob", Comment: "Hm", Kind: predictions.WagerAndComment},
+	}
+	var b bytes.Buffer
+	err := WriteResponses(&b, responses, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+
+	expected := "7,100,0.25,Bob,Hm\n"
+	if b.String() != expected {
+		t.Errorf("Incorrect output; should be %q, was %q", expected, b.String())
+	}
+}
+
+func TestWritePredictionsOptions(t *testing.T) {
+	t.Parallel()
+
+	options := &Options{
+		Header:        true,
+		Columns:       []string{"title", "id", "deadline", "outcome", "mean_confidence", "creator_slug"},
+		TimeFormat:    DateOnly,
+		OutcomeFormat: OutcomeLabel,
+		NaN:           "",
+	}
+	var b bytes.Buffer
+	err := WritePredictions(&b, testPredictions, options)
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+
+	expected := "title,id,deadline,outcome,mean_confidence,creator_slug\n\"Title, with comma\",7,2021-01-02,wrong,,alice\n"
+	if b.String() != expected {
+		t.Errorf("Incorrect output; should be %q, was %q", expected, b.String())
+	}
+
+	options.Columns = []string{"nonexistent"}
+	err = WritePredictions(&b, testPredictions, options)
+	if err == nil {
+		t.Errorf("Error should have been returned for an unknown column")
+	}
+}
+
+func TestReadPredictions(t *testing.T) {
+	t.Parallel()
+
+	for _, options := range []*Options{
+		DefaultOptions(),
+		{Header: true, TimeFormat: RFC3339Time, OutcomeFormat: OutcomeLabel, NaN: "NA", Columns: []string{"deadline", "id", "created", "outcome", "title", "mean_confidence"}},
+	} {
+		var b bytes.Buffer
+		err := WritePredictions(&b, testPredictions, options)
+		if err != nil {
+			t.Fatalf("Error writing should have been nil, was %s", err)
+		}
+
+		ps, err := ReadPredictions(&b)
+		if err != nil {
+			t.Fatalf("Error reading should have been nil, was %s", err)
+		}
+		if len(ps) != 1 {
+			t.Fatalf("Incorrect number of predictions read; should be 1, was %d", len(ps))
+		}
+		p, e := ps[0], testPredictions[0]
+		if p.Id != e.Id || p.Title != e.Title || !p.Created.Equal(e.Created) || !p.Deadline.Equal(e.Deadline) || p.Outcome != e.Outcome || !math.IsNaN(p.MeanConfidence) {
+			t.Errorf("Incorrect prediction read with options %+v, was %+v", options, p)
+		}
+	}
+}
+
+func TestWriteResponsesConfidence(t *testing.T) {
+	t.Parallel()
+
+	responses := []*predictions.PredictionResponse{
+		{Prediction: 7, Time: time.Unix(100, 0), Confidence: 0.25, User: "Bob", Kind: predictions.WagerOnly},
+		{Prediction: 7, Time: time.Unix(200, 0), Confidence: math.NaN(), User: "Carol", Kind: predictions.CommentOnly},
+	}
+	var b bytes.Buffer
+	err := WriteResponses(&b, responses, &Options{Columns: []string{"user", "confidence", "time"}, TimeFormat: RFC3339Time, NaN: "null"})
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+
+	expected := "Bob,0.25,1970-01-01T00:01:40Z\nCarol,null,1970-01-01T00:03:20Z\n"
+	if b.String() != expected {
+		t.Errorf("Incorrect output; should be %q, was %q", expected, b.String())
+	}
+}
+
+func TestReadPredictionsWithoutOutcome(t *testing.T) {
+	t.Parallel()
+
+	ps, err := ReadPredictions(bytes.NewBufferString("id,title,deadline\n7,Title,2021-01-02\n"))
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+	if len(ps) != 1 {
+		t.Fatalf("Incorrect number of predictions read; should be 1, was %d", len(ps))
+	}
+	if ps[0].Outcome != predictions.Unknown || ps[0].Title != "Title" {
+		t.Errorf("Incorrect prediction read, was %+v", ps[0])
+	}
+}
+
+func TestReadResponsesBaseline(t *testing.T) {
+	t.Parallel()
+
+	// Exports made before options were added had five columns and no header.
+	input := "7,100,0.25,Bob,\n7,200,NaN,Carol,Hm\n7,300,0.5,Dave,Sure\n"
+	responses, err := ReadResponses(bytes.NewBufferString(input))
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("Incorrect number of responses read; should be 3, was %d", len(responses))
+	}
+	for i, kind := range []predictions.ResponseKind{predictions.WagerOnly, predictions.CommentOnly, predictions.WagerAndComment} {
+		if responses[i].Kind != kind {
+			t.Errorf("Incorrect kind for response %d; should be %s, was %s", i, kind, responses[i].Kind)
+		}
+	}
+	if responses[1].User != "Carol" || responses[1].Comment != "Hm" || !responses[1].Time.Equal(time.Unix(200, 0)) {
+		t.Errorf("Incorrect response read, was %+v", responses[1])
+	}
+}
+
+func TestReadResponsesColumnSubset(t *testing.T) {
+	t.Parallel()
+
+	responses := []*predictions.PredictionResponse{
+		{Prediction: 7, Time: time.Unix(100, 0), Confidence: 0.25, User: "Bob", Kind: predictions.WagerOnly},
+		{Prediction: 7, Time: time.Unix(200, 0), Confidence: math.NaN(), User: "Carol", Comment: "Hm", Kind: predictions.CommentOnly},
+	}
+	var b bytes.Buffer
+	err := WriteResponses(&b, responses, &Options{Header: true, Columns: []string{"prediction", "user", "confidence", "comment"}, NaN: "NaN"})
+	if err != nil {
+		t.Fatalf("Error writing should have been nil, was %s", err)
+	}
+
+	read, err := ReadResponses(&b)
+	if err != nil {
+		t.Fatalf("Error reading should have been nil, was %s", err)
+	}
+	if len(read) != 2 {
+		t.Fatalf("Incorrect number of responses read; should be 2, was %d", len(read))
+	}
+	for i, r := range read {
+		if r.User != responses[i].User || r.Kind != responses[i].Kind || !r.Time.IsZero() {
+			t.Errorf("Incorrect response %d read, was %+v", i, r)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package export writes predictions, responses and data quality findings as CSV, with
+// shared options for headers, columns and value formats, and reads them back.
+package export
+
+import (
+	"errors"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TimeFormat int64
+
+const (
+	UnixTime TimeFormat = iota
+	RFC3339Time
+	DateOnly
+)
+
+func (f TimeFormat) String() string {
+	switch f {
+	case UnixTime:
+		return "unix"
+	case RFC3339Time:
+		return "rfc3339"
+	case DateOnly:
+		return "date"
+	default:
+		return "unknown"
+	}
+}
+
+func ParseTimeFormat(s string) (TimeFormat, error) {
+	for _, f := range []TimeFormat{UnixTime, RFC3339Time, DateOnly} {
+		if f.String() == s {
+			return f, nil
+		}
+	}
+	return 0, errors.New("unknown time format " + s + "; should be unix, rfc3339 or date")
+}
+
+type OutcomeFormat int64
+
+const (
+	OutcomeCode OutcomeFormat = iota
+	OutcomeLabel
+)
+
+func (f OutcomeFormat) String() string {
+	switch f {
+	case OutcomeCode:
+		return "code"
+	case OutcomeLabel:
+		return "label"
+	default:
+		return "unknown"
+	}
+}
+
+func ParseOutcomeFormat(s string) (OutcomeFormat, error) {
+	for _, f := range []OutcomeFormat{OutcomeCode, OutcomeLabel} {
+		if f.String() == s {
+			return f, nil
+		}
+	}
+	return 0, errors.New("unknown outcome format " + s + "; should be code or label")
+}
+
+// Options control how records are written. The zero value, apart from NaN, writes every
+// default column without a header, times as Unix seconds and outcomes by their code.
+type Options struct {
+	Header bool
+	// Columns selects and orders the columns written, by name; if empty, the default columns are written.
+	Columns       []string
+	TimeFormat    TimeFormat
+	OutcomeFormat OutcomeFormat
+	// NaN is written for unknown confidences.
+	NaN string
+}
+
+func DefaultOptions() *Options {
+	return &Options{NaN: "NaN"}
+}
+
+// ParseColumns splits a comma-separated list of column names, returning nil if it is empty.
+func ParseColumns(s string) []string {
+	var columns []string
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		if c != "" {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// formatTime writes times in the chosen format. In formats other than Unix seconds, zero
+// times are left empty; they are kept as Unix seconds for compatibility with older exports.
+func (o *Options) formatTime(t time.Time) string {
+	switch o.TimeFormat {
+	case RFC3339Time:
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format(time.RFC3339)
+	case DateOnly:
+		if t.IsZero() {
+			return ""
+		}
+		return t.UTC().Format("2006-01-02")
+	default:
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+}
+
+func (o *Options) formatFloat(f float64) string {
+	if math.IsNaN(f) {
+		return o.NaN
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func (o *Options) formatOutcome(outcome predictions.Outcome) string {
+	if o.OutcomeFormat == OutcomeLabel {
+		return outcome.String()
+	}
+	return strconv.FormatInt(int64(outcome), 10)
+}
@@ -0,0 +1,195 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReadPredictions reads predictions written with any options. With a header row, columns
+// are found by name; without one, columns are expected in their default order. Times may be in any
+// of the time formats, outcomes labels or codes, and non-numeric confidences are unknown.
+// Missing columns are left unset, with a missing or empty outcome read as unknown.
+func ReadPredictions(r io.Reader) (ps []*predictions.PredictionSummary, err error) {
+	err = readTable(r, PredictionColumns, func(row *tableRow) {
+		p := &predictions.PredictionSummary{
+			Id:               row.int("id"),
+			Created:          row.time("created"),
+			Deadline:         row.time("deadline"),
+			MeanConfidence:   row.float("mean_confidence"),
+			WagerCount:       row.int("wager_count"),
+			Outcome:          row.outcome("outcome"),
+			Creator:          row.string("creator"),
+			CreatorSlug:      row.string("creator_slug"),
+			Title:            row.string("title"),
+			Topics:           strings.Fields(row.string("topics")),
+			DuplicateCluster: row.int("duplicate_cluster"),
+			Judged:           row.time("judged"),
+		}
+		p.Details.Text = row.string("details")
+		p.Details.Urls = strings.Fields(row.string("details_urls"))
+		ps = append(ps, p)
+	})
+	return ps, err
+}
+
+// ReadResponses reads prediction responses written with any options, as ReadPredictions does.
+// Without a kind, as in exports made before kinds were recorded, it is worked out from the
+// confidence and comment as the extractor does.
+func ReadResponses(r io.Reader) (responses []*predictions.PredictionResponse, err error) {
+	err = readTable(r, ResponseColumns, func(row *tableRow) {
+		response := &predictions.PredictionResponse{
+			Prediction:      row.int("prediction"),
+			Time:            row.time("time"),
+			Confidence:      row.float("confidence"),
+			User:            row.string("user"),
+			UserSlug:        row.string("user_slug"),
+			Comment:         row.string("comment"),
+			CommentMarkdown: row.string("comment_markdown"),
+			Urls:            strings.Fields(row.string("urls")),
+			Mentions:        strings.Fields(row.string("mentions")),
+		}
+		response.Kind = row.kind("kind", predictions.ResponseKindFor(response.Confidence, response.Comment != ""))
+		responses = append(responses, response)
+	})
+	return responses, err
+}
+
+// tableRow looks up values by column name, recording the first which can't be parsed.
+type tableRow struct {
+	columns map[string]int
+	record  []string
+	err     error
+}
+
+func readTable(r io.Reader, defaults []string, read func(row *tableRow)) error {
+	csvReader := csv.NewReader(r)
+	row := &tableRow{columns: make(map[string]int)}
+	for i, name := range defaults {
+		row.columns[name] = i
+	}
+
+	line := 0
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line++
+
+		// A header row is recognised by its non-numeric first field, as every record type starts with an id
+		if line == 1 {
+			if _, err := strconv.ParseInt(record[0], 10, 64); err != nil {
+				row.columns = make(map[string]int)
+				for i, name := range record {
+					row.columns[name] = i
+				}
+				continue
+			}
+		}
+
+		row.record = record
+		read(row)
+		if row.err != nil {
+			return fmt.Errorf("line %d: %s", line, row.err)
+		}
+	}
+}
+
+func (row *tableRow) string(name string) string {
+	i, ok := row.columns[name]
+	if !ok || i >= len(row.record) {
+		return ""
+	}
+	return row.record[i]
+}
+
+func (row *tableRow) fail(name, value string) {
+	if row.err == nil {
+		row.err = fmt.Errorf("invalid %s %q", name, value)
+	}
+}
+
+func (row *tableRow) int(name string) int64 {
+	value := row.string(name)
+	if value == "" {
+		return 0
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		row.fail(name, value)
+	}
+	return i
+}
+
+func (row *tableRow) float(name string) float64 {
+	f, err := strconv.ParseFloat(row.string(name), 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return f
+}
+
+func (row *tableRow) time(name string) time.Time {
+	value := row.string(name)
+	t, err := ParseTime(value)
+	if err != nil {
+		row.fail(name, value)
+	}
+	return t
+}
+
+func (row *tableRow) outcome(name string) predictions.Outcome {
+	value := row.string(name)
+	if value == "" {
+		return predictions.Unknown
+	}
+	for _, o := range []predictions.Outcome{predictions.Unknown, predictions.Right, predictions.Wrong} {
+		if value == o.String() || value == strconv.FormatInt(int64(o), 10) {
+			return o
+		}
+	}
+	row.fail(name, value)
+	return predictions.Unknown
+}
+
+// kind returns the response kind, or derived if it is missing or empty.
+func (row *tableRow) kind(name string, derived predictions.ResponseKind) predictions.ResponseKind {
+	value := row.string(name)
+	if value == "" {
+		return derived
+	}
+	var k predictions.ResponseKind
+	err := k.UnmarshalText([]byte(value))
+	if err != nil {
+		row.fail(name, value)
+	}
+	return k
+}
+
+// ParseTime parses a time in any of the time formats, or an empty string as the zero time.
+func ParseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, errors.New("should be Unix seconds, RFC 3339 or a date")
+	}
+	return t, nil
+}
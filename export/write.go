@@ -0,0 +1,156 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type column struct {
+	name  string
+	value func(i int) string
+}
+
+// Names of the columns of each record type, in their default order. Columns after
+// the defaults are only written when selected.
+var (
+	PredictionColumns = []string{"id", "created", "deadline", "mean_confidence", "wager_count", "outcome", "creator", "title", "details", "details_urls", "topics", "duplicate_cluster", "creator_slug", "judged"}
+	ResponseColumns   = []string{"prediction", "time", "confidence", "user", "comment", "comment_markdown", "urls", "mentions", "kind", "user_slug"}
+	FindingColumns    = []string{"prediction", "field", "list_value", "page_value"}
+)
+
+// The default columns are those exports have always had, so existing consumers can parse them.
+const (
+	defaultPredictionColumns = 8
+	defaultResponseColumns   = 5
+)
+
+func predictionColumns(ps []*predictions.PredictionSummary, o *Options) []column {
+	return []column{
+		{"id", func(i int) string { return strconv.FormatInt(ps[i].Id, 10) }},
+		{"created", func(i int) string { return o.formatTime(ps[i].Created) }},
+		{"deadline", func(i int) string { return o.formatTime(ps[i].Deadline) }},
+		{"mean_confidence", func(i int) string { return o.formatFloat(ps[i].MeanConfidence) }},
+		{"wager_count", func(i int) string { return strconv.FormatInt(ps[i].WagerCount, 10) }},
+		{"outcome", func(i int) string { return o.formatOutcome(ps[i].Outcome) }},
+		{"creator", func(i int) string { return ps[i].Creator }},
+		{"title", func(i int) string { return ps[i].Title }},
+		{"details", func(i int) string { return ps[i].Details.Text }},
+		{"details_urls", func(i int) string { return strings.Join(ps[i].Details.Urls, " ") }},
+		{"topics", func(i int) string { return strings.Join(ps[i].Topics, " ") }},
+		// Left empty for predictions without near-duplicates
+		{"duplicate_cluster", func(i int) string {
+			if ps[i].DuplicateCluster == 0 {
+				return ""
+			}
+			return strconv.FormatInt(ps[i].DuplicateCluster, 10)
+		}},
+		{"creator_slug", func(i int) string { return ps[i].CreatorSlug }},
+		{"judged", func(i int) string { return o.formatTime(ps[i].Judged) }},
+	}
+}
+
+func responseColumns(responses []*predictions.PredictionResponse, o *Options) []column {
+	return []column{
+		{"prediction", func(i int) string { return strconv.FormatInt(responses[i].Prediction, 10) }},
+		{"time", func(i int) string { return o.formatTime(responses[i].Time) }},
+		{"confidence", func(i int) string { return o.formatFloat(responses[i].Confidence) }},
+		{"user", func(i int) string { return responses[i].User }},
+		{"comment", func(i int) string { return responses[i].Comment }},
+		{"comment_markdown", func(i int) string { return responses[i].CommentMarkdown }},
+		{"urls", func(i int) string { return strings.Join(responses[i].Urls, " ") }},
+		{"mentions", func(i int) string { return strings.Join(responses[i].Mentions, " ") }},
+		{"kind", func(i int) string { return responses[i].Kind.String() }},
+		{"user_slug", func(i int) string { return responses[i].UserSlug }},
+	}
+}
+
+func findingColumns(findings []*predictions.DataQualityFinding) []column {
+	return []column{
+		{"prediction", func(i int) string { return strconv.FormatInt(findings[i].Prediction, 10) }},
+		{"field", func(i int) string { return findings[i].Field }},
+		{"list_value", func(i int) string { return findings[i].ListValue }},
+		{"page_value", func(i int) string { return findings[i].PageValue }},
+	}
+}
+
+func WritePredictions(w io.Writer, ps []*predictions.PredictionSummary, o *Options) error {
+	return writeTable(w, predictionColumns(ps, o), defaultPredictionColumns, len(ps), o)
+}
+
+func WriteResponses(w io.Writer, responses []*predictions.PredictionResponse, o *Options) error {
+	return writeTable(w, responseColumns(responses, o), defaultResponseColumns, len(responses), o)
+}
+
+func WriteFindings(w io.Writer, findings []*predictions.DataQualityFinding, o *Options) error {
+	return writeTable(w, findingColumns(findings), len(FindingColumns), len(findings), o)
+}
+
+// CheckColumns returns an error if any selected column isn't one of the available columns.
+func CheckColumns(selected, available []string) error {
+	_, err := selectColumns(selected, available)
+	return err
+}
+
+func selectColumns(selected, available []string) ([]int, error) {
+	var indexes []int
+	for _, name := range selected {
+		found := false
+		for i, a := range available {
+			if a == name {
+				indexes = append(indexes, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("unknown column " + name + "; should be one of " + strings.Join(available, ", "))
+		}
+	}
+	return indexes, nil
+}
+
+func writeTable(w io.Writer, columns []column, defaults int, rows int, o *Options) error {
+	selected := columns[:defaults]
+	if len(o.Columns) > 0 {
+		var names []string
+		for _, c := range columns {
+			names = append(names, c.name)
+		}
+		indexes, err := selectColumns(o.Columns, names)
+		if err != nil {
+			return err
+		}
+		selected = nil
+		for _, i := range indexes {
+			selected = append(selected, columns[i])
+		}
+	}
+
+	csvWriter := csv.NewWriter(w)
+	record := make([]string, len(selected))
+	if o.Header {
+		for j, c := range selected {
+			record[j] = c.name
+		}
+		err := csvWriter.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	for i := 0; i < rows; i++ {
+		for j, c := range selected {
+			record[j] = c.value(i)
+		}
+		err := csvWriter.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,137 @@
+package feed
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type Feed struct {
+	Id      string
+	Title   string
+	Link    string
+	Updated time.Time
+	Entries []*Entry
+}
+
+type Entry struct {
+	Id      string
+	Title   string
+	Link    string
+	Author  string
+	Updated time.Time
+	Summary string
+}
+
+// CreatedFeed lists the most recently created predictions, newest first.
+// An empty feed is treated as updated when the predictions were crawled.
+func CreatedFeed(baseUrl string, crawled time.Time, ps []*predictions.PredictionSummary, limit int) *Feed {
+	var entries []*Entry
+	for _, p := range ps {
+		entries = append(entries, predictionEntry(baseUrl, p, p.Created, "Created by "+p.Creator+", known on "+p.Deadline.Format("2006-01-02")))
+	}
+
+	return newFeed(baseUrl+"/predictions#created", baseUrl+"/predictions", "Recently created predictions", crawled, entries, limit)
+}
+
+// JudgedFeed lists the most recently judged predictions, newest first. Predictions
+// without a known judgement time are left out.
+func JudgedFeed(baseUrl string, crawled time.Time, ps []*predictions.PredictionSummary, limit int) *Feed {
+	var entries []*Entry
+	for _, p := range ps {
+		if p.Outcome == predictions.Unknown || p.Judged.IsZero() {
+			continue
+		}
+
+		entries = append(entries, predictionEntry(baseUrl, p, p.Judged, "Judged "+p.Outcome.String()))
+	}
+
+	return newFeed(baseUrl+"/predictions#judged", baseUrl+"/predictions", "Recently judged predictions", crawled, entries, limit)
+}
+
+// UserFeed lists a user's activity, newest first: predictions they created, and their responses.
+func UserFeed(baseUrl string, crawled time.Time, slug string, ps []*predictions.PredictionSummary, responses []*predictions.PredictionResponse, limit int) *Feed {
+	titles := make(map[int64]string)
+	name := slug
+	var entries []*Entry
+	for _, p := range ps {
+		titles[p.Id] = p.Title
+		if p.CreatorSlug != slug {
+			continue
+		}
+
+		name = p.Creator
+		entries = append(entries, predictionEntry(baseUrl, p, p.Created, "Created, known on "+p.Deadline.Format("2006-01-02")))
+	}
+
+	for _, r := range responses {
+		if r.UserSlug != slug {
+			continue
+		}
+
+		name = r.User
+		link := predictionLink(baseUrl, r.Prediction)
+		entries = append(entries, &Entry{
+			Id:      link + "#response-" + strconv.FormatInt(r.Time.Unix(), 10),
+			Title:   titles[r.Prediction],
+			Link:    link,
+			Author:  r.User,
+			Updated: r.Time,
+			Summary: responseSummary(r),
+		})
+	}
+
+	link := baseUrl + "/users/" + slug
+	return newFeed(link, link, "Activity by "+name, crawled, entries, limit)
+}
+
+func newFeed(id, link, title string, crawled time.Time, entries []*Entry, limit int) *Feed {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Updated.After(entries[j].Updated)
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	f := &Feed{
+		Id:      id,
+		Title:   title,
+		Link:    link,
+		Updated: crawled,
+		Entries: entries,
+	}
+	if len(entries) > 0 && !entries[0].Updated.IsZero() {
+		f.Updated = entries[0].Updated
+	}
+
+	return f
+}
+
+func predictionEntry(baseUrl string, p *predictions.PredictionSummary, updated time.Time, summary string) *Entry {
+	return &Entry{
+		Id:      predictionLink(baseUrl, p.Id),
+		Title:   p.Title,
+		Link:    predictionLink(baseUrl, p.Id),
+		Author:  p.Creator,
+		Updated: updated,
+		Summary: summary,
+	}
+}
+
+func predictionLink(baseUrl string, prediction int64) string {
+	return baseUrl + "/predictions/" + strconv.FormatInt(prediction, 10)
+}
+
+func responseSummary(r *predictions.PredictionResponse) string {
+	estimate := strconv.Itoa(int(math.Round(r.Confidence*100))) + "%"
+	switch r.Kind {
+	case predictions.WagerOnly:
+		return "Estimated " + estimate
+	case predictions.WagerAndComment:
+		return "Estimated " + estimate + " and said: " + r.Comment
+	default:
+		return "Said: " + r.Comment
+	}
+}
@@ -0,0 +1,181 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"testing"
+	"time"
+)
+
+func testSummaries() []*predictions.PredictionSummary {
+	return []*predictions.PredictionSummary{
+		{
+			Id:          1,
+			Title:       "First",
+			Creator:     "Michael Dickens",
+			CreatorSlug: "MTGandP",
+			Created:     time.Unix(1000, 0),
+			Deadline:    time.Unix(5000, 0),
+			Judged:      time.Unix(6000, 0),
+			Outcome:     predictions.Right,
+		},
+		{
+			Id:          2,
+			Title:       "Second",
+			Creator:     "jbeshir",
+			CreatorSlug: "jbeshir",
+			Created:     time.Unix(2000, 0),
+			Deadline:    time.Unix(3000, 0),
+		},
+		{
+			Id:          3,
+			Title:       "Third",
+			Creator:     "jbeshir",
+			CreatorSlug: "jbeshir",
+			Created:     time.Unix(1500, 0),
+			Judged:      time.Unix(7000, 0),
+			Outcome:     predictions.Wrong,
+		},
+	}
+}
+
+func TestCreatedFeed(t *testing.T) {
+	t.Parallel()
+
+	f := CreatedFeed("https://example.org", time.Unix(9000, 0), testSummaries(), 2)
+	if len(f.Entries) != 2 {
+		t.Fatalf("Incorrect number of entries; should be %d, was %d", 2, len(f.Entries))
+	}
+	if f.Entries[0].Link != "https://example.org/predictions/2" {
+		t.Errorf("Incorrect first entry link; should be %s, was %s", "https://example.org/predictions/2", f.Entries[0].Link)
+	}
+	if f.Entries[1].Title != "Third" {
+		t.Errorf("Incorrect second entry title; should be %s, was %s", "Third", f.Entries[1].Title)
+	}
+	if !f.Updated.Equal(time.Unix(2000, 0)) {
+		t.Errorf("Incorrect feed updated time; should be %d, was %d", 2000, f.Updated.Unix())
+	}
+}
+
+func TestJudgedFeed(t *testing.T) {
+	t.Parallel()
+
+	f := JudgedFeed("https://example.org", time.Unix(9000, 0), testSummaries(), 0)
+	if len(f.Entries) != 2 {
+		t.Fatalf("Incorrect number of entries; should be %d, was %d", 2, len(f.Entries))
+	}
+	if f.Entries[0].Title != "Third" || f.Entries[0].Summary != "Judged wrong" {
+		t.Errorf("Incorrect first entry; should be Third judged wrong, was %+v", f.Entries[0])
+	}
+}
+
+func TestUserFeed(t *testing.T) {
+	t.Parallel()
+
+	responses := []*predictions.PredictionResponse{
+		{Prediction: 1, User: "jbeshir", UserSlug: "jbeshir", Time: time.Unix(2500, 0), Confidence: 0.3, Kind: predictions.WagerOnly},
+		{Prediction: 1, User: "Michael Dickens", UserSlug: "MTGandP", Time: time.Unix(2600, 0), Confidence: 0.4, Kind: predictions.WagerOnly},
+	}
+
+	f := UserFeed("https://example.org", time.Unix(9000, 0), "jbeshir", testSummaries(), responses, 0)
+	if len(f.Entries) != 3 {
+		t.Fatalf("Incorrect number of entries; should be %d, was %d", 3, len(f.Entries))
+	}
+	if f.Entries[0].Title != "First" || f.Entries[0].Summary != "Estimated 30%" {
+		t.Errorf("Incorrect first entry; should be an estimate on First, was %+v", f.Entries[0])
+	}
+	if f.Link != "https://example.org/users/jbeshir" {
+		t.Errorf("Incorrect feed link; should be %s, was %s", "https://example.org/users/jbeshir", f.Link)
+	}
+}
+
+func TestWriteAtom(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := WriteAtom(&buf, CreatedFeed("https://example.org", time.Unix(9000, 0), testSummaries(), 0))
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+
+	var parsed atomFeed
+	err = xml.Unmarshal(buf.Bytes(), &parsed)
+	if err != nil {
+		t.Fatalf("Couldn't parse written feed: %s", err)
+	}
+	if len(parsed.Entries) != 3 {
+		t.Fatalf("Incorrect number of entries; should be %d, was %d", 3, len(parsed.Entries))
+	}
+	if parsed.Entries[0].Link.Href != "https://example.org/predictions/2" {
+		t.Errorf("Incorrect first entry link; should be %s, was %s", "https://example.org/predictions/2", parsed.Entries[0].Link.Href)
+	}
+	if parsed.Entries[0].Updated != "1970-01-01T00:33:20Z" {
+		t.Errorf("Incorrect first entry updated time; should be %s, was %s", "1970-01-01T00:33:20Z", parsed.Entries[0].Updated)
+	}
+}
+
+func TestWriteRss(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := WriteRss(&buf, JudgedFeed("https://example.org", time.Unix(9000, 0), testSummaries(), 0))
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+
+	var parsed rssFeed
+	err = xml.Unmarshal(buf.Bytes(), &parsed)
+	if err != nil {
+		t.Fatalf("Couldn't parse written feed: %s", err)
+	}
+	if parsed.Version != "2.0" {
+		t.Errorf("Incorrect RSS version; should be %s, was %s", "2.0", parsed.Version)
+	}
+	if len(parsed.Channel.Items) != 2 {
+		t.Fatalf("Incorrect number of items; should be %d, was %d", 2, len(parsed.Channel.Items))
+	}
+	if parsed.Channel.Items[1].Guid.Value != "https://example.org/predictions/1" {
+		t.Errorf("Incorrect second item guid; should be %s, was %s", "https://example.org/predictions/1", parsed.Channel.Items[1].Guid.Value)
+	}
+}
+
+func TestFeedIds(t *testing.T) {
+	t.Parallel()
+
+	created := CreatedFeed("https://example.org", time.Unix(9000, 0), testSummaries(), 0)
+	judged := JudgedFeed("https://example.org", time.Unix(9000, 0), testSummaries(), 0)
+	if created.Id == judged.Id {
+		t.Errorf("Created and judged feeds should have different ids, both were %s", created.Id)
+	}
+	if created.Link != judged.Link {
+		t.Errorf("Created and judged feeds should link to the same page; were %s and %s", created.Link, judged.Link)
+	}
+}
+
+func TestEmptyFeedUpdated(t *testing.T) {
+	t.Parallel()
+
+	f := JudgedFeed("https://example.org", time.Unix(9000, 0), nil, 0)
+	if !f.Updated.Equal(time.Unix(9000, 0)) {
+		t.Errorf("Incorrect feed updated time; should be %d, was %d", 9000, f.Updated.Unix())
+	}
+}
+
+func TestResponseSummaryRounding(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		confidence float64
+		summary    string
+	}{
+		{0.07, "Estimated 7%"},
+		{0.3, "Estimated 30%"},
+	}
+	for _, test := range tests {
+		summary := responseSummary(&predictions.PredictionResponse{Confidence: test.confidence, Kind: predictions.WagerOnly})
+		if summary != test.summary {
+			t.Errorf("Incorrect summary for %v; should be %s, was %s", test.confidence, test.summary, summary)
+		}
+	}
+}
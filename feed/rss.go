@@ -0,0 +1,60 @@
+package feed
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	LastBuildDate string     `xml:"lastBuildDate,omitempty"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Guid        rssGuid `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func WriteRss(w io.Writer, f *Feed) error {
+	rf := &rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Title,
+		},
+	}
+	if !f.Updated.IsZero() {
+		rf.Channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, e := range f.Entries {
+		rf.Channel.Items = append(rf.Channel.Items, &rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			Guid:        rssGuid{IsPermaLink: e.Id == e.Link, Value: e.Id},
+			PubDate:     e.Updated.UTC().Format(time.RFC1123Z),
+			Description: e.Author + ": " + e.Summary,
+		})
+	}
+
+	return writeXml(w, rf)
+}
@@ -2,6 +2,5 @@ module github.com/jbeshir/predictionbook-extractor
 
 require (
 	github.com/PuerkitoBio/goquery v1.5.0
-	github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b
 	golang.org/x/net v0.0.0-20190206173232-65e2d4e15006
 )
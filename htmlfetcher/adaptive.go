@@ -0,0 +1,94 @@
+package htmlfetcher
+
+import (
+	"golang.org/x/time/rate"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// Latency averages are exponentially weighted, the baseline much more slowly than
+	// the recent average so that it reflects the server's usual responsiveness.
+	recentLatencyWeight   = 0.2
+	baselineLatencyWeight = 0.02
+	// Latency is considered raised once the recent average is this multiple of the baseline.
+	latencyBackoffFactor = 2
+	// Samples needed to establish a baseline before latency causes backing off.
+	minLatencySamples = 5
+
+	backoffInterval  = 5 * time.Second
+	recoveryInterval = 10 * time.Second
+	// The rate recovers by this fraction of the configured rate each recovery interval.
+	recoveryFraction = 0.05
+	// The rate never drops below this fraction of the configured rate.
+	minLimitFraction = 1.0 / 32
+)
+
+// adaptiveLimit lowers a limiter's rate when the server signals it is overloaded, by
+// rate limiting responses or by slowing down, and slowly raises it back afterwards.
+type adaptiveLimit struct {
+	mu      sync.Mutex
+	limiter *rate.Limiter
+	max     rate.Limit
+	now     func() time.Time
+
+	recentLatency   float64
+	baselineLatency float64
+	samples         int
+	lastChange      time.Time
+}
+
+func newAdaptiveLimit(limiter *rate.Limiter, now func() time.Time) *adaptiveLimit {
+	return &adaptiveLimit{
+		limiter:    limiter,
+		max:        limiter.Limit(),
+		now:        now,
+		lastChange: now(),
+	}
+}
+
+// observe adjusts the rate given a response's status code and latency.
+func (a *adaptiveLimit) observe(status int, latency time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+		// Explicit overload always backs off, however recently we last did
+		a.setLimit(a.limiter.Limit()/2, now)
+		return
+	}
+
+	seconds := latency.Seconds()
+	a.samples++
+	if a.samples == 1 {
+		a.recentLatency, a.baselineLatency = seconds, seconds
+		return
+	}
+	a.recentLatency += recentLatencyWeight * (seconds - a.recentLatency)
+
+	if a.samples >= minLatencySamples && a.recentLatency > latencyBackoffFactor*a.baselineLatency {
+		if now.Sub(a.lastChange) >= backoffInterval {
+			a.setLimit(a.limiter.Limit()*3/4, now)
+		}
+		return
+	}
+
+	// Only healthy latencies count towards the baseline, so it isn't dragged up under load
+	a.baselineLatency += baselineLatencyWeight * (seconds - a.baselineLatency)
+	if a.limiter.Limit() < a.max && now.Sub(a.lastChange) >= recoveryInterval {
+		a.setLimit(a.limiter.Limit()+a.max*recoveryFraction, now)
+	}
+}
+
+func (a *adaptiveLimit) setLimit(limit rate.Limit, now time.Time) {
+	if limit > a.max {
+		limit = a.max
+	}
+	if limit < a.max*minLimitFraction {
+		limit = a.max * minLimitFraction
+	}
+	a.limiter.SetLimit(limit)
+	a.lastChange = now
+}
@@ -0,0 +1,74 @@
+package htmlfetcher
+
+import (
+	"golang.org/x/time/rate"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	t time.Time
+}
+
+func (c *testClock) now() time.Time {
+	return c.t
+}
+
+func TestAdaptiveLimitRateLimited(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{time.Unix(0, 0)}
+	limiter := rate.NewLimiter(8, 1)
+	a := newAdaptiveLimit(limiter, clock.now)
+
+	a.observe(http.StatusTooManyRequests, time.Second)
+	a.observe(http.StatusTooManyRequests, time.Second)
+	if limiter.Limit() != 2 {
+		t.Errorf("Incorrect limit after two 429s; should be %g, was %g", 2.0, float64(limiter.Limit()))
+	}
+
+	for i := 0; i < 10; i++ {
+		a.observe(http.StatusTooManyRequests, time.Second)
+	}
+	if limiter.Limit() != 0.25 {
+		t.Errorf("Incorrect limit after repeated 429s; should be floor of %g, was %g", 0.25, float64(limiter.Limit()))
+	}
+
+	// Recovery only happens once per interval
+	a.observe(http.StatusOK, 100*time.Millisecond)
+	if limiter.Limit() != 0.25 {
+		t.Errorf("Incorrect limit immediately after backing off; should be %g, was %g", 0.25, float64(limiter.Limit()))
+	}
+	for i := 0; i < 100; i++ {
+		clock.t = clock.t.Add(recoveryInterval)
+		a.observe(http.StatusOK, 100*time.Millisecond)
+	}
+	if limiter.Limit() != 8 {
+		t.Errorf("Incorrect limit after recovering; should be %g, was %g", 8.0, float64(limiter.Limit()))
+	}
+}
+
+func TestAdaptiveLimitLatency(t *testing.T) {
+	t.Parallel()
+
+	clock := &testClock{time.Unix(0, 0)}
+	limiter := rate.NewLimiter(4, 1)
+	a := newAdaptiveLimit(limiter, clock.now)
+
+	for i := 0; i < 20; i++ {
+		clock.t = clock.t.Add(time.Second)
+		a.observe(http.StatusOK, 100*time.Millisecond)
+	}
+	if limiter.Limit() != 4 {
+		t.Fatalf("Incorrect limit with steady latency; should be %g, was %g", 4.0, float64(limiter.Limit()))
+	}
+
+	for i := 0; i < 20; i++ {
+		clock.t = clock.t.Add(time.Second)
+		a.observe(http.StatusOK, time.Second)
+	}
+	if limiter.Limit() >= 4 {
+		t.Errorf("Limit should have been lowered when latency rose, was %g", float64(limiter.Limit()))
+	}
+}
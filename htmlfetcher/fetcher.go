@@ -2,23 +2,39 @@ package htmlfetcher
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"github.com/golang/glog"
+	"github.com/jbeshir/predictionbook-extractor/logging"
 	"golang.org/x/net/html"
 	"golang.org/x/time/rate"
+	"io"
 	"net/http"
+	"net/url"
+	"time"
 )
 
 type Fetcher struct {
 	limiter          *rate.Limiter
 	requestTokenPool chan bool
+	adaptive         *adaptiveLimit
+	metrics          *Metrics
+	logger           logging.Logger
+	client           *http.Client
+
+	// Set by Restrict; until then any URL may be fetched.
+	scope  *url.URL
+	robots *robotsRules
 }
 
-func NewFetcher(limiter *rate.Limiter, concurrentRequestLimit int) *Fetcher {
+// NewFetcher returns a fetcher making at most concurrentRequestLimit requests at once, at
+// the limiter's rate. Requests are logged at debug level to the logger, which may be nil.
+func NewFetcher(limiter *rate.Limiter, concurrentRequestLimit int, logger logging.Logger) *Fetcher {
 	f := &Fetcher{
 		limiter:          limiter,
 		requestTokenPool: make(chan bool, concurrentRequestLimit),
+		logger:           logging.OrDiscard(logger),
 	}
+	f.client = &http.Client{CheckRedirect: f.checkRedirect}
 
 	for i := 0; i < concurrentRequestLimit; i++ {
 		f.requestTokenPool <- true
@@ -27,47 +43,199 @@ func NewFetcher(limiter *rate.Limiter, concurrentRequestLimit int) *Fetcher {
 	return f
 }
 
-func (f *Fetcher) GetHtml(ctx context.Context, url string) (*html.Node, error) {
+// NewAdaptiveFetcher returns a fetcher which backs off from the limiter's rate when the
+// server responds with 429 or 503 statuses or its latency rises, and slowly recovers to
+// it once responses are healthy again.
+func NewAdaptiveFetcher(limiter *rate.Limiter, concurrentRequestLimit int, logger logging.Logger) *Fetcher {
+	f := NewFetcher(limiter, concurrentRequestLimit, logger)
+	f.adaptive = newAdaptiveLimit(limiter, time.Now)
+	return f
+}
+
+// Restrict refuses requests for URLs outside the scheme and host of the base URL. Unless
+// ignoreRobots is set, it also retrieves the host's robots.txt, refusing requests for paths
+// it disallows and lowering the rate limit to honour any crawl delay. A robots.txt which
+// is missing is treated as allowing everything, but one which can't be retrieved is an error.
+func (f *Fetcher) Restrict(ctx context.Context, baseUrl string, ignoreRobots bool) error {
+	scope, err := url.Parse(baseUrl)
+	if err != nil {
+		return err
+	}
+	if scope.Scheme == "" || scope.Host == "" {
+		return errors.New("base URL must be absolute: " + baseUrl)
+	}
+	f.scope = scope
+	if ignoreRobots {
+		return nil
+	}
+
+	robots, err := f.retrieveRobots(ctx)
+	if err != nil {
+		return errors.New("Couldn't retrieve robots.txt: " + err.Error())
+	}
+	f.robots = robots
 
+	if robots.crawlDelay > 0 {
+		delayLimit := rate.Every(robots.crawlDelay)
+		if delayLimit < f.limiter.Limit() {
+			f.logger.Info("Honouring robots.txt crawl delay", "crawl_delay", robots.crawlDelay)
+			f.limiter.SetLimit(delayLimit)
+			f.limiter.SetBurst(1)
+			if f.adaptive != nil {
+				f.adaptive.mu.Lock()
+				f.adaptive.max = delayLimit
+				f.adaptive.mu.Unlock()
+			}
+		}
+	}
+	return nil
+}
+
+func (f *Fetcher) retrieveRobots(ctx context.Context) (*robotsRules, error) {
 	err := f.limiter.Wait(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	robotsUrl := &url.URL{Scheme: f.scope.Scheme, Host: f.scope.Host, Path: "/robots.txt"}
+	resp, err := f.do(ctx, robotsUrl.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return parseRobots(resp.Body)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return new(robotsRules), nil
+	default:
+		return nil, errors.New("HTTP error: " + resp.Status)
+	}
+}
+
+func (f *Fetcher) GetHtml(ctx context.Context, url string) (rootNode *html.Node, err error) {
+	err = f.get(ctx, url, func(body io.Reader) error {
+		rootNode, err = html.Parse(body)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rootNode, nil
+}
+
+func (f *Fetcher) GetJson(ctx context.Context, url string, v interface{}) error {
+	return f.get(ctx, url, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(v)
+	})
+}
+
+func (f *Fetcher) get(ctx context.Context, url string, parse func(body io.Reader) error) error {
+
+	err := f.checkAllowed(url)
+	if err != nil {
+		return err
+	}
+
+	waitStart := time.Now()
+	err = f.limiter.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	f.metrics.observeLimiterWait(time.Since(waitStart))
+
 	<-f.requestTokenPool
 	defer func() {
 		f.requestTokenPool <- true
 	}()
+	f.metrics.requestStarted()
+	defer f.metrics.requestFinished()
 
-	if glog.V(2) {
-		glog.Infoln("Retrieving", url)
-	}
+	logUrl := redactUrl(url)
+	f.logger.Debug("Retrieving", logging.UrlKey, logUrl)
 
-	resp, err := http.Get(url)
+	start := time.Now()
+	resp, err := f.do(ctx, url)
 	if err != nil {
-		if glog.V(2) {
-			glog.Infof("HTTP request error: %s\n", err)
-		}
-		return nil, err
+		f.metrics.observeResponse(0, time.Since(start))
+		f.logger.Debug("HTTP request error", logging.UrlKey, logUrl, logging.DurationKey, time.Since(start), logging.ErrorKey, err)
+		return err
 	}
 	defer resp.Body.Close()
+	f.metrics.observeResponse(resp.StatusCode, time.Since(start))
+	if f.adaptive != nil {
+		f.adaptive.observe(resp.StatusCode, time.Since(start))
+	}
 	if resp.StatusCode != 200 {
-		if glog.V(2) {
-			glog.Infof("HTTP error: %s\n", resp.Status)
-		}
-		return nil, errors.New("HTTP error: " + resp.Status)
+		f.logger.Debug("HTTP error", logging.UrlKey, logUrl, logging.DurationKey, time.Since(start), logging.StatusKey, resp.StatusCode)
+		return errors.New("HTTP error: " + resp.Status)
 	}
 
-	rootNode, err := html.Parse(resp.Body)
+	err = parse(f.metrics.countBody(resp.Body))
 	if err != nil {
-		if glog.V(2) {
-			glog.Infof("Parse error: %s\n", err.Error())
-		}
-		return nil, errors.New("Parse error: " + err.Error())
+		f.logger.Debug("Parse error", logging.UrlKey, logUrl, logging.DurationKey, time.Since(start), logging.ErrorKey, err)
+		return errors.New("Parse error: " + err.Error())
 	}
 
-	if glog.V(2) {
-		glog.Infoln("Retrieved", url)
+	f.logger.Debug("Retrieved", logging.UrlKey, logUrl, logging.DurationKey, time.Since(start), logging.StatusKey, resp.StatusCode)
+	return nil
+}
+
+// redactUrl hides any API token in a URL, so it isn't written to logs or returned in errors.
+func redactUrl(rawUrl string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return rawUrl
 	}
-	return rootNode, nil
+	query := u.Query()
+	if query.Get("api_token") == "" {
+		return rawUrl
+	}
+	query.Set("api_token", "REDACTED")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// checkAllowed returns an error if the URL is outside the fetcher's scope or disallowed
+// by robots.txt.
+func (f *Fetcher) checkAllowed(rawUrl string) error {
+	if f.scope == nil {
+		return nil
+	}
+
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != f.scope.Scheme || u.Host != f.scope.Host {
+		return errors.New("Refusing to fetch URL outside " + f.scope.Host + ": " + redactUrl(rawUrl))
+	}
+	if f.robots != nil && !f.robots.allowed(u.RequestURI()) {
+		return errors.New("Refusing to fetch URL disallowed by robots.txt: " + redactUrl(rawUrl))
+	}
+	return nil
+}
+
+// checkRedirect applies the fetcher's restrictions to each redirect, as well as the
+// default limit of ten redirects.
+func (f *Fetcher) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	return f.checkAllowed(req.URL.String())
+}
+
+func (f *Fetcher) do(ctx context.Context, rawUrl string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, rawUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	resp, err := f.client.Do(req.WithContext(ctx))
+	if urlErr, ok := err.(*url.Error); ok {
+		urlErr.URL = redactUrl(urlErr.URL)
+	}
+	return resp, err
 }
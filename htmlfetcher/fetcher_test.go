@@ -0,0 +1,54 @@
+package htmlfetcher
+
+import (
+	"context"
+	"golang.org/x/time/rate"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestErrorsRedactApiToken(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte(testRobots))
+			return
+		}
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	f := NewFetcher(rate.NewLimiter(100, 5), 1, nil)
+	err := f.Restrict(context.Background(), server.URL, false)
+	if err != nil {
+		t.Fatalf("Error restricting fetcher: %s", err)
+	}
+
+	urls := []string{
+		server.URL + "/predictions/new?api_token=secret",
+		"https://example.org/api/predictions?api_token=secret",
+	}
+	for _, u := range urls {
+		_, err = f.GetHtml(context.Background(), u)
+		if err == nil {
+			t.Fatalf("Expected error fetching %s", u)
+		}
+		if strings.Contains(err.Error(), "secret") {
+			t.Errorf("Error should not contain the API token, was: %s", err)
+		}
+	}
+
+	// Connection errors from the HTTP client include the URL too.
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	_, err = NewFetcher(rate.NewLimiter(100, 5), 1, nil).GetHtml(context.Background(), closed.URL+"/api/predictions?api_token=secret")
+	if err == nil {
+		t.Fatalf("Expected error fetching from a closed server")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("Error should not contain the API token, was: %s", err)
+	}
+}
@@ -0,0 +1,87 @@
+package htmlfetcher
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/metrics"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Metrics instruments a Fetcher's requests.
+type Metrics struct {
+	requests    *metrics.Counter
+	latency     *metrics.Histogram
+	limiterWait *metrics.Histogram
+	inFlight    *metrics.Gauge
+	bytes       *metrics.Counter
+}
+
+// NewMetrics registers the fetcher's metrics. Requests are counted by HTTP status code,
+// or "error" if no response was received.
+func NewMetrics(r *metrics.Registry) *Metrics {
+	return &Metrics{
+		requests:    r.NewCounter("predictionbook_http_requests_total", "HTTP requests made, by response status.", "status"),
+		latency:     r.NewHistogram("predictionbook_http_request_duration_seconds", "Time from sending each HTTP request to receiving its response headers.", metrics.DefaultLatencyBuckets),
+		limiterWait: r.NewHistogram("predictionbook_http_limiter_wait_seconds", "Time each HTTP request waited for the rate limiter.", metrics.DefaultLatencyBuckets),
+		inFlight:    r.NewGauge("predictionbook_http_requests_in_flight", "HTTP requests currently being made."),
+		bytes:       r.NewCounter("predictionbook_http_response_bytes_total", "Bytes of HTTP response bodies read."),
+	}
+}
+
+// SetMetrics instruments the fetcher's requests with the given metrics, or stops if nil.
+func (f *Fetcher) SetMetrics(m *Metrics) {
+	f.metrics = m
+}
+
+func (m *Metrics) observeLimiterWait(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.limiterWait.Observe(d.Seconds())
+}
+
+func (m *Metrics) requestStarted() {
+	if m == nil {
+		return
+	}
+	m.inFlight.Add(1)
+}
+
+func (m *Metrics) requestFinished() {
+	if m == nil {
+		return
+	}
+	m.inFlight.Add(-1)
+}
+
+// observeResponse records a request's outcome, with a status of zero if it failed.
+func (m *Metrics) observeResponse(status int, latency time.Duration) {
+	if m == nil {
+		return
+	}
+	statusLabel := "error"
+	if status != 0 {
+		statusLabel = strconv.Itoa(status)
+	}
+	m.requests.Inc(statusLabel)
+	m.latency.Observe(latency.Seconds())
+}
+
+// countBody wraps a response body to count the bytes read from it.
+func (m *Metrics) countBody(body io.Reader) io.Reader {
+	if m == nil {
+		return body
+	}
+	return &countingReader{r: body, bytes: m.bytes}
+}
+
+type countingReader struct {
+	r     io.Reader
+	bytes *metrics.Counter
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.bytes.Add(float64(n))
+	return n, err
+}
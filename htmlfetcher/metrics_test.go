@@ -0,0 +1,52 @@
+package htmlfetcher
+
+import (
+	"bytes"
+	"context"
+	"github.com/jbeshir/predictionbook-extractor/metrics"
+	"golang.org/x/time/rate"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetcherMetrics(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("<html><body>Hello</body></html>"))
+	}))
+	defer server.Close()
+
+	registry := metrics.NewRegistry()
+	f := NewFetcher(rate.NewLimiter(rate.Inf, 1), 1, nil)
+	f.SetMetrics(NewMetrics(registry))
+
+	for _, path := range []string{"/", "/", "/missing"} {
+		f.GetHtml(context.Background(), server.URL+path)
+	}
+
+	var b bytes.Buffer
+	err := registry.Write(&b)
+	if err != nil {
+		t.Fatalf("Error writing metrics: %s", err)
+	}
+	output := b.String()
+	for _, line := range []string{
+		`predictionbook_http_requests_total{status="200"} 2`,
+		`predictionbook_http_requests_total{status="404"} 1`,
+		`predictionbook_http_request_duration_seconds_count 3`,
+		`predictionbook_http_limiter_wait_seconds_count 3`,
+		`predictionbook_http_requests_in_flight 0`,
+		`predictionbook_http_response_bytes_total 62`,
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Incorrect metrics; should include %s, were:\n%s", line, output)
+		}
+	}
+}
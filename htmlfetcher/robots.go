@@ -0,0 +1,135 @@
+package htmlfetcher
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UserAgent identifies the fetcher's requests, and is the name it looks for in robots.txt.
+const UserAgent = "predictionbook-extractor"
+
+// robotsRules are the rules from a robots.txt which apply to UserAgent.
+type robotsRules struct {
+	rules      []*robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+type robotsGroup struct {
+	agents     []string
+	rules      []*robotsRule
+	crawlDelay time.Duration
+}
+
+// parseRobots parses a robots.txt, selecting the group naming UserAgent if there is one,
+// or else the group for all agents.
+func parseRobots(r io.Reader) (*robotsRules, error) {
+	var groups []*robotsGroup
+	var current *robotsGroup
+	inAgents := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if comment := strings.Index(line, "#"); comment != -1 {
+			line = line[:comment]
+		}
+		split := strings.Index(line, ":")
+		if split == -1 {
+			continue
+		}
+		field := strings.ToLower(strings.TrimSpace(line[:split]))
+		value := strings.TrimSpace(line[split+1:])
+
+		if field == "user-agent" {
+			// Consecutive user-agent lines share a group
+			if !inAgents {
+				current = new(robotsGroup)
+				groups = append(groups, current)
+				inAgents = true
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			continue
+		}
+		inAgents = false
+		if current == nil {
+			continue
+		}
+
+		switch field {
+		case "allow", "disallow":
+			if value == "" {
+				continue
+			}
+			current.rules = append(current.rules, &robotsRule{
+				allow:   field == "allow",
+				length:  len(value),
+				pattern: robotsPattern(value),
+			})
+		case "crawl-delay":
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err == nil && seconds > 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+	}
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := new(robotsRules)
+	var wildcard *robotsGroup
+	for _, g := range groups {
+		for _, agent := range g.agents {
+			if agent == "*" && wildcard == nil {
+				wildcard = g
+			}
+			if agent == UserAgent {
+				rules.rules, rules.crawlDelay = g.rules, g.crawlDelay
+				return rules, nil
+			}
+		}
+	}
+	if wildcard != nil {
+		rules.rules, rules.crawlDelay = wildcard.rules, wildcard.crawlDelay
+	}
+	return rules, nil
+}
+
+// robotsPattern converts a robots.txt path pattern, in which * matches anything and
+// a trailing $ anchors the end, into a regular expression matching path prefixes.
+func robotsPattern(path string) *regexp.Regexp {
+	anchored := strings.HasSuffix(path, "$")
+	path = strings.TrimSuffix(path, "$")
+
+	expr := "^" + strings.Replace(regexp.QuoteMeta(path), `\*`, ".*", -1)
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allowed reports whether a path, including any query string, may be fetched.
+// The longest matching rule applies, with allow rules winning ties.
+func (r *robotsRules) allowed(path string) bool {
+	var best *robotsRule
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if best == nil || rule.length > best.length || (rule.length == best.length && rule.allow) {
+			best = rule
+		}
+	}
+	return best == nil || best.allow
+}
@@ -0,0 +1,180 @@
+package htmlfetcher
+
+import (
+	"context"
+	"golang.org/x/time/rate"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRobots = `# Example robots.txt
+User-agent: *
+Disallow: /
+
+User-agent: Googlebot
+User-agent: predictionbook-extractor
+Disallow: /users/*/settings
+Disallow: /predictions/new
+Disallow: /*.json$
+Allow: /predictions/new/help
+Crawl-delay: 2
+`
+
+func TestParseRobots(t *testing.T) {
+	t.Parallel()
+
+	robots, err := parseRobots(strings.NewReader(testRobots))
+	if err != nil {
+		t.Fatalf("Error parsing robots.txt: %s", err)
+	}
+	if robots.crawlDelay != 2*time.Second {
+		t.Errorf("Incorrect crawl delay; should be %s, was %s", 2*time.Second, robots.crawlDelay)
+	}
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/", true},
+		{"/predictions/page/2", true},
+		{"/predictions/new", false},
+		{"/predictions/new?foo=bar", false},
+		{"/predictions/new/help", true},
+		{"/users/jbeshir/settings", false},
+		{"/users/jbeshir", true},
+		{"/predictions.json", false},
+		{"/predictions.json?page=2", true},
+	}
+	for _, test := range tests {
+		if robots.allowed(test.path) != test.allowed {
+			t.Errorf("Incorrect result for %s; should be allowed %t", test.path, test.allowed)
+		}
+	}
+}
+
+func TestParseRobotsWildcardGroup(t *testing.T) {
+	t.Parallel()
+
+	robots, err := parseRobots(strings.NewReader("User-agent: otherbot\nDisallow: /\n\nUser-agent: *\nDisallow: /private\n"))
+	if err != nil {
+		t.Fatalf("Error parsing robots.txt: %s", err)
+	}
+	if !robots.allowed("/public") || robots.allowed("/private/page") {
+		t.Errorf("Rules for all agents should apply when none name the fetcher")
+	}
+}
+
+func TestRestrict(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != UserAgent {
+			t.Errorf("Incorrect user agent; should be %s, was %s", UserAgent, r.Header.Get("User-Agent"))
+		}
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte(testRobots))
+			return
+		}
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	f := NewFetcher(rate.NewLimiter(100, 5), 1, nil)
+	err := f.Restrict(context.Background(), server.URL, false)
+	if err != nil {
+		t.Fatalf("Error restricting fetcher: %s", err)
+	}
+	if f.limiter.Limit() != 0.5 || f.limiter.Burst() != 1 {
+		t.Errorf("Incorrect limit after crawl delay; should be %g with burst %d, was %g with burst %d", 0.5, 1, float64(f.limiter.Limit()), f.limiter.Burst())
+	}
+
+	_, err = f.GetHtml(context.Background(), server.URL+"/predictions/page/2")
+	if err != nil {
+		t.Errorf("Error fetching allowed page: %s", err)
+	}
+	_, err = f.GetHtml(context.Background(), server.URL+"/predictions/new")
+	if err == nil {
+		t.Errorf("Expected error fetching page disallowed by robots.txt")
+	}
+	_, err = f.GetHtml(context.Background(), "https://example.org/predictions/page/2")
+	if err == nil {
+		t.Errorf("Expected error fetching page on another host")
+	}
+}
+
+func TestRestrictIgnoringRobots(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			t.Errorf("robots.txt should not be retrieved when ignored")
+		}
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	f := NewFetcher(rate.NewLimiter(100, 5), 1, nil)
+	err := f.Restrict(context.Background(), server.URL, true)
+	if err != nil {
+		t.Fatalf("Error restricting fetcher: %s", err)
+	}
+
+	_, err = f.GetHtml(context.Background(), server.URL+"/predictions/new")
+	if err != nil {
+		t.Errorf("Error fetching page with robots.txt ignored: %s", err)
+	}
+	_, err = f.GetHtml(context.Background(), "https://example.org/predictions/new")
+	if err == nil {
+		t.Errorf("Expected error fetching page on another host")
+	}
+}
+
+func TestRestrictFollowsRedirects(t *testing.T) {
+	t.Parallel()
+
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Redirect to another host should not have been followed")
+	}))
+	defer foreign.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte(testRobots))
+		case "/predictions/page/2":
+			http.Redirect(w, r, "/predictions/page/3", http.StatusFound)
+		case "/predictions/page/3":
+			w.Write([]byte("<html></html>"))
+		case "/predictions/page/4":
+			http.Redirect(w, r, "/predictions/new", http.StatusFound)
+		case "/predictions/page/5":
+			http.Redirect(w, r, foreign.URL+"/predictions/page/5", http.StatusFound)
+		default:
+			t.Errorf("Request for %s should not have been made", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	f := NewFetcher(rate.NewLimiter(100, 5), 1, nil)
+	err := f.Restrict(context.Background(), server.URL, false)
+	if err != nil {
+		t.Fatalf("Error restricting fetcher: %s", err)
+	}
+	f.limiter.SetLimit(100)
+
+	_, err = f.GetHtml(context.Background(), server.URL+"/predictions/page/2")
+	if err != nil {
+		t.Errorf("Error following allowed redirect: %s", err)
+	}
+	_, err = f.GetHtml(context.Background(), server.URL+"/predictions/page/4")
+	if err == nil {
+		t.Errorf("Expected error following redirect to page disallowed by robots.txt")
+	}
+	_, err = f.GetHtml(context.Background(), server.URL+"/predictions/page/5")
+	if err == nil {
+		t.Errorf("Expected error following redirect to another host")
+	}
+}
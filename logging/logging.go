@@ -0,0 +1,41 @@
+// Package logging defines the structured logger accepted by the fetcher, sources and
+// watcher, so library users choose where their logs go.
+package logging
+
+// Logger is the subset of *slog.Logger's methods used here, so a *slog.Logger may be
+// passed directly. Arguments after the message alternate between keys and values.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Keys for fields logged by more than one package, so they are named consistently.
+const (
+	UrlKey          = "url"
+	PredictionIdKey = "prediction_id"
+	PageKey         = "page"
+	AttemptKey      = "attempt"
+	DurationKey     = "duration"
+	StatusKey       = "status"
+	ErrorKey        = "error"
+)
+
+// Discard is a Logger which drops everything logged.
+var Discard Logger = discard{}
+
+type discard struct{}
+
+func (discard) Debug(msg string, args ...interface{}) {}
+func (discard) Info(msg string, args ...interface{})  {}
+func (discard) Warn(msg string, args ...interface{})  {}
+func (discard) Error(msg string, args ...interface{}) {}
+
+// OrDiscard returns the logger, or Discard if it is nil.
+func OrDiscard(l Logger) Logger {
+	if l == nil {
+		return Discard
+	}
+	return l
+}
@@ -0,0 +1,29 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	var logger Logger = slog.New(slog.NewJSONHandler(&b, nil))
+	logger.Info("Retrieved", UrlKey, "https://example.org/", AttemptKey, 2)
+
+	if !strings.Contains(b.String(), `"url":"https://example.org/","attempt":2`) {
+		t.Errorf("Incorrect log output; should include url and attempt fields, was %s", b.String())
+	}
+}
+
+func TestOrDiscard(t *testing.T) {
+	t.Parallel()
+
+	if OrDiscard(nil) != Discard {
+		t.Errorf("Nil logger should be replaced by Discard")
+	}
+	OrDiscard(nil).Warn("Dropped", PageKey, 1)
+}
@@ -0,0 +1,335 @@
+// Package metrics implements counters, gauges and histograms exposed in the Prometheus
+// text exposition format, either over HTTP or written to a file for node_exporter's
+// textfile collector.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets are histogram bucket upper bounds in seconds suited to HTTP requests.
+var DefaultLatencyBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+type metricType int64
+
+const (
+	counterType metricType = iota
+	gaugeType
+	histogramType
+)
+
+func (t metricType) String() string {
+	switch t {
+	case counterType:
+		return "counter"
+	case gaugeType:
+		return "gauge"
+	case histogramType:
+		return "histogram"
+	default:
+		return "untyped"
+	}
+}
+
+// Registry holds metrics, writing them out in the order they were created.
+type Registry struct {
+	mu       sync.Mutex
+	families []*family
+}
+
+func NewRegistry() *Registry {
+	return new(Registry)
+}
+
+// family is a named metric, with one series per distinct combination of label values.
+type family struct {
+	name       string
+	help       string
+	kind       metricType
+	labelNames []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	// For histograms, non-cumulative counts per bucket, with a final +Inf bucket
+	bucketCounts []uint64
+	count        uint64
+}
+
+type Counter struct{ f *family }
+type Gauge struct{ f *family }
+type Histogram struct{ f *family }
+
+// NewCounter creates a metric which only increases.
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	return &Counter{r.register(name, help, counterType, labelNames, nil)}
+}
+
+// NewGauge creates a metric which can go up and down.
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	return &Gauge{r.register(name, help, gaugeType, labelNames, nil)}
+}
+
+// NewHistogram creates a metric counting observations into buckets with the given
+// increasing upper bounds.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if !sort.Float64sAreSorted(buckets) {
+		panic("metrics: histogram buckets for " + name + " are not in increasing order")
+	}
+	return &Histogram{r.register(name, help, histogramType, labelNames, buckets)}
+}
+
+// register adds a metric, or returns the existing one if the name is already registered
+// with the same type, labels and buckets, so several fetchers or sources can share a
+// registry. Registering a name again with a different definition panics.
+func (r *Registry) register(name, help string, kind metricType, labelNames []string, buckets []float64) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, f := range r.families {
+		if f.name != name {
+			continue
+		}
+		if f.kind != kind || !equalStrings(f.labelNames, labelNames) || !equalFloats(f.buckets, buckets) {
+			panic("metrics: " + name + " is already registered with a different definition")
+		}
+		return f
+	}
+	f := &family{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		buckets:    buckets,
+		series:     make(map[string]*series),
+	}
+	r.families = append(r.families, f)
+	return f
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalFloats(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Inc adds one to the series with the given label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds a non-negative amount to the series with the given label values.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic("metrics: counter " + c.f.name + " decreased")
+	}
+	c.f.update(labelValues, func(s *series) {
+		s.value += v
+	})
+}
+
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.f.update(labelValues, func(s *series) {
+		s.value = v
+	})
+}
+
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.f.update(labelValues, func(s *series) {
+		s.value += v
+	})
+}
+
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.f.update(labelValues, func(s *series) {
+		i := sort.SearchFloat64s(h.f.buckets, v)
+		s.bucketCounts[i]++
+		s.count++
+		s.value += v
+	})
+}
+
+func (f *family) update(labelValues []string, apply func(s *series)) {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, was given %d", f.name, len(f.labelNames), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if f.kind == histogramType {
+			s.bucketCounts = make([]uint64, len(f.buckets)+1)
+		}
+		f.series[key] = s
+	}
+	apply(s)
+}
+
+// Write writes every metric in the text exposition format. Series within each metric
+// are sorted by their label values, so output is stable between writes.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	families := append([]*family(nil), r.families...)
+	r.mu.Unlock()
+
+	for _, f := range families {
+		_, err := io.WriteString(w, f.format())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *family) format() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.kind)
+
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// Metrics without labels are reported as zero before anything is recorded
+	if len(keys) == 0 && len(f.labelNames) == 0 {
+		s := &series{bucketCounts: make([]uint64, len(f.buckets)+1)}
+		f.formatSeries(&b, s)
+	}
+	for _, k := range keys {
+		f.formatSeries(&b, f.series[k])
+	}
+	return b.String()
+}
+
+func (f *family) formatSeries(b *strings.Builder, s *series) {
+	if f.kind != histogramType {
+		fmt.Fprintf(b, "%s%s %s\n", f.name, formatLabels(f.labelNames, s.labelValues, "", ""), formatValue(s.value))
+		return
+	}
+
+	cumulative := uint64(0)
+	for i, count := range s.bucketCounts {
+		cumulative += count
+		le := "+Inf"
+		if i < len(f.buckets) {
+			le = formatValue(f.buckets[i])
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", f.name, formatLabels(f.labelNames, s.labelValues, "le", le), cumulative)
+	}
+	fmt.Fprintf(b, "%s_sum%s %s\n", f.name, formatLabels(f.labelNames, s.labelValues, "", ""), formatValue(s.value))
+	fmt.Fprintf(b, "%s_count%s %d\n", f.name, formatLabels(f.labelNames, s.labelValues, "", ""), s.count)
+}
+
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	var pairs []string
+	for i, name := range names {
+		pairs = append(pairs, name+`="`+escapeLabelValue(values[i])+`"`)
+	}
+	if extraName != "" {
+		pairs = append(pairs, extraName+`="`+escapeLabelValue(extraValue)+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelValueEscaper.Replace(s)
+}
+
+// ServeHTTP serves the metrics, so a registry can be mounted at /metrics.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	err := r.Write(w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WriteFile writes the metrics to a file, replacing it atomically so the textfile
+// collector never reads a partial file. Its name should end in .prom to be collected.
+func (r *Registry) WriteFile(path string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = r.Write(tmp)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(tmp.Name(), 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	requests := r.NewCounter("requests_total", "Requests made, by status.", "status")
+	inFlight := r.NewGauge("in_flight", "Requests in flight.")
+	latency := r.NewHistogram("latency_seconds", "Request latency.\nIn seconds.", []float64{0.125, 1})
+
+	requests.Inc("200")
+	requests.Add(2, "200")
+	requests.Inc(`5"0\0`)
+	latency.Observe(0.0625)
+	latency.Observe(0.125)
+	latency.Observe(3)
+
+	var b bytes.Buffer
+	err := r.Write(&b)
+	if err != nil {
+		t.Fatalf("Error writing metrics: %s", err)
+	}
+
+	expected := `# HELP requests_total Requests made, by status.
+# TYPE requests_total counter
+requests_total{status="200"} 3
+requests_total{status="5\"0\\0"} 1
+# HELP in_flight Requests in flight.
+# TYPE in_flight gauge
+in_flight 0
+# HELP latency_seconds Request latency.\nIn seconds.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.125"} 2
+latency_seconds_bucket{le="1"} 2
+latency_seconds_bucket{le="+Inf"} 3
+latency_seconds_sum 3.1875
+latency_seconds_count 3
+`
+	if b.String() != expected {
+		t.Errorf("Incorrect output; should be:\n%s\nwas:\n%s", expected, b.String())
+	}
+
+	inFlight.Add(1)
+	recorder := httptest.NewRecorder()
+	r.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if !bytes.Contains(recorder.Body.Bytes(), []byte("in_flight 1\n")) {
+		t.Errorf("Incorrect served metrics; should include gauge value 1, were:\n%s", recorder.Body.String())
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "metrics")
+	if err != nil {
+		t.Fatalf("Error creating temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := NewRegistry()
+	r.NewCounter("items_total", "Items.", "kind").Add(5, "prediction")
+
+	path := filepath.Join(dir, "crawl.prom")
+	err = r.WriteFile(path)
+	if err != nil {
+		t.Fatalf("Error writing metrics file: %s", err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading metrics file: %s", err)
+	}
+	if !bytes.Contains(content, []byte(`items_total{kind="prediction"} 5`)) {
+		t.Errorf("Incorrect metrics file content:\n%s", content)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("Incorrect number of files left; should be 1, was %d", len(files))
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	first := r.NewCounter("requests_total", "Requests made, by status.", "status")
+	second := r.NewCounter("requests_total", "Requests made, by status.", "status")
+	first.Inc("200")
+	second.Inc("200")
+
+	var b bytes.Buffer
+	err := r.Write(&b)
+	if err != nil {
+		t.Fatalf("Error writing metrics: %s", err)
+	}
+	if !bytes.Contains(b.Bytes(), []byte("requests_total{status=\"200\"} 2\n")) || bytes.Count(b.Bytes(), []byte("# TYPE")) != 1 {
+		t.Errorf("Metrics registered twice should share one series, were:\n%s", b.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Registering a name with a different definition should have panicked")
+		}
+	}()
+	r.NewGauge("requests_total", "Requests made.")
+}
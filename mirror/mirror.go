@@ -0,0 +1,233 @@
+package mirror
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"github.com/jbeshir/predictionbook-extractor/scoring"
+	"github.com/jbeshir/predictionbook-extractor/store"
+	"html/template"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PageSize is the number of predictions on each index page, as on PredictionBook.
+const PageSize = 50
+
+// page holds everything any of the templates may render.
+type page struct {
+	Title   string
+	Root    string
+	BaseUrl string
+	Crawled time.Time
+
+	Index    int
+	LastPage int
+
+	Predictions []*predictions.PredictionSummary
+	Prediction  *predictions.PredictionSummary
+	Responses   []*predictions.PredictionResponse
+	User        *scoring.UserSummary
+	Users       []*scoring.UserSummary
+}
+
+func (p *page) Prev() int {
+	return p.Index - 1
+}
+
+func (p *page) Next() int {
+	return p.Index + 1
+}
+
+type userLink struct {
+	Root string
+	Slug string
+	Name string
+}
+
+// Generate writes a static HTML mirror of the dataset to the given directory, laid out as
+// index.html and predictions/page/N/ for the prediction lists, predictions/ID/ for each
+// prediction with its responses and judgement history, and users/SLUG/ for each user.
+// All links are relative, so the mirror can be browsed from disk or served from any path.
+func Generate(dir string, dataset *store.Dataset) error {
+	base := page{
+		BaseUrl: dataset.BaseUrl,
+		Crawled: dataset.Crawled,
+	}
+
+	// Newest first, as the site lists them
+	ps := make([]*predictions.PredictionSummary, len(dataset.Predictions))
+	copy(ps, dataset.Predictions)
+	sort.SliceStable(ps, func(i, j int) bool {
+		return ps[i].Id > ps[j].Id
+	})
+
+	lastPage := (len(ps) + PageSize - 1) / PageSize
+	if lastPage == 0 {
+		lastPage = 1
+	}
+	for i := 1; i <= lastPage; i++ {
+		p := base
+		p.Title = "Recent Predictions"
+		p.Index = i
+		p.LastPage = lastPage
+		p.Predictions = ps[(i-1)*PageSize : minInt(i*PageSize, len(ps))]
+
+		p.Root = "../../../"
+		err := writePage(dir, listTemplate, &p, "predictions", "page", strconv.Itoa(i))
+		if err != nil {
+			return err
+		}
+		if i == 1 {
+			p.Root = ""
+			err = writePage(dir, listTemplate, &p)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	responses := make(map[int64][]*predictions.PredictionResponse)
+	for _, r := range dataset.Responses {
+		responses[r.Prediction] = append(responses[r.Prediction], r)
+	}
+	for _, prediction := range ps {
+		p := base
+		p.Title = prediction.Title
+		p.Root = "../../"
+		p.Prediction = prediction
+		p.Responses = responses[prediction.Id]
+		err := writePage(dir, predictionTemplate, &p, "predictions", strconv.FormatInt(prediction.Id, 10))
+		if err != nil {
+			return err
+		}
+	}
+
+	users := allUsers(ps, dataset.Responses)
+	created := make(map[string][]*predictions.PredictionSummary)
+	for _, prediction := range ps {
+		created[prediction.CreatorSlug] = append(created[prediction.CreatorSlug], prediction)
+	}
+	for _, u := range users {
+		p := base
+		p.Title = u.Name
+		p.Root = "../../"
+		p.User = u
+		p.Predictions = created[u.Slug]
+		err := writePage(dir, userTemplate, &p, "users", u.Slug)
+		if err != nil {
+			return err
+		}
+	}
+
+	p := base
+	p.Title = "Users"
+	p.Root = "../"
+	p.Users = users
+	return writePage(dir, userListTemplate, &p, "users")
+}
+
+// allUsers returns a summary for every user with a page linked to from the mirror,
+// including those who only commented or judged and so have no wagers to score.
+func allUsers(ps []*predictions.PredictionSummary, responses []*predictions.PredictionResponse) []*scoring.UserSummary {
+	users := scoring.UserSummaries(ps, responses)
+	known := make(map[string]bool)
+	for _, u := range users {
+		known[u.Slug] = true
+	}
+
+	addUser := func(slug, name string) {
+		if slug == "" || known[slug] {
+			return
+		}
+		known[slug] = true
+		users = append(users, &scoring.UserSummary{
+			Slug:        slug,
+			Name:        name,
+			BrierScore:  math.NaN(),
+			Calibration: scoring.Calibration(nil, nil),
+		})
+	}
+	for _, r := range responses {
+		addUser(r.UserSlug, r.User)
+	}
+	for _, p := range ps {
+		for _, j := range p.Judgements {
+			addUser(j.UserSlug, j.User)
+		}
+	}
+
+	var valid []*scoring.UserSummary
+	for _, u := range users {
+		if ValidSlug(u.Slug) {
+			valid = append(valid, u)
+		}
+	}
+	sort.Slice(valid, func(i, j int) bool {
+		return valid[i].Slug < valid[j].Slug
+	})
+	return valid
+}
+
+// ValidSlug reports whether a slug can safely be used as a file or directory name.
+func ValidSlug(slug string) bool {
+	return slug != "" && slug != "." && slug != ".." && !strings.ContainsAny(slug, `/\`)
+}
+
+func writePage(dir string, t *template.Template, p *page, path ...string) error {
+	pageDir := filepath.Join(append([]string{dir}, path...)...)
+	err := os.MkdirAll(pageDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(pageDir, "index.html"))
+	if err != nil {
+		return err
+	}
+
+	err = t.Execute(f, p)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func newUserLink(root, slug, name string) userLink {
+	if !ValidSlug(slug) {
+		slug = ""
+	}
+	return userLink{Root: root, Slug: slug, Name: name}
+}
+
+// userPath returns the relative link to a user's page. Slugs are taken from the site's own
+// links and may already be escaped, so are escaped again to match the directory name.
+func userPath(slug string) string {
+	return "users/" + url.PathEscape(slug) + "/index.html"
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+func formatPercentage(f float64) string {
+	if math.IsNaN(f) {
+		return ""
+	}
+	return strconv.Itoa(int(math.Round(f*100))) + "%"
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
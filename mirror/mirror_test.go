@@ -0,0 +1,111 @@
+package mirror
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"github.com/jbeshir/predictionbook-extractor/store"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testDataset() *store.Dataset {
+	dataset := &store.Dataset{
+		BaseUrl: "https://example.org",
+		Crawled: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		Predictions: []*predictions.PredictionSummary{
+			{
+				Id:             1,
+				Title:          "The election will be held in <May>",
+				Creator:        "jbeshir",
+				CreatorSlug:    "jbeshir",
+				Created:        time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+				Deadline:       time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC),
+				MeanConfidence: 0.8,
+				WagerCount:     1,
+				Outcome:        predictions.Right,
+				Judgements: []*predictions.PredictionJudgement{
+					{Prediction: 1, User: "Judge", UserSlug: "judge", Outcome: predictions.Right},
+				},
+			},
+		},
+		Responses: []*predictions.PredictionResponse{
+			{Prediction: 1, User: "jbeshir", UserSlug: "jbeshir", Confidence: 0.8, Kind: predictions.WagerOnly},
+			{Prediction: 1, User: "Azt", UserSlug: "azt57@psu%5Bdot%5Dedu", Confidence: math.NaN(), Kind: predictions.CommentOnly, Comment: "Unlikely"},
+		},
+	}
+	for i := int64(2); i <= PageSize+1; i++ {
+		dataset.Predictions = append(dataset.Predictions, &predictions.PredictionSummary{
+			Id:             i,
+			Title:          "Filler prediction",
+			MeanConfidence: math.NaN(),
+		})
+	}
+	return dataset
+}
+
+func readPage(t *testing.T, dir string, path ...string) string {
+	content, err := ioutil.ReadFile(filepath.Join(append(append([]string{dir}, path...), "index.html")...))
+	if err != nil {
+		t.Fatalf("Couldn't read page %v: %s", path, err)
+	}
+	return string(content)
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "mirror")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = Generate(dir, testDataset())
+	if err != nil {
+		t.Fatalf("Couldn't generate mirror: %s", err)
+	}
+
+	index := readPage(t, dir)
+	if strings.Count(index, `<li class="prediction`) != PageSize {
+		t.Errorf("Incorrect number of predictions on index page; should be %d, was %d", PageSize, strings.Count(index, `<li class="prediction`))
+	}
+	if !strings.Contains(index, `href="predictions/page/2/index.html"`) {
+		t.Errorf("Index page should link to the second page")
+	}
+
+	lastPage := readPage(t, dir, "predictions", "page", "2")
+	if !strings.Contains(lastPage, `href="../../../predictions/1/index.html"`) {
+		t.Errorf("Second page should link to the oldest prediction")
+	}
+	if !strings.Contains(lastPage, "The election will be held in &lt;May&gt;") {
+		t.Errorf("Second page should list the oldest prediction with its title escaped")
+	}
+
+	prediction := readPage(t, dir, "predictions", "1")
+	for _, expected := range []string{
+		`<span class="confidence">80%</span>`,
+		`<span class="comment">Unlikely</span>`,
+		`href="../../users/azt57@psu%255Bdot%255Dedu/index.html"`,
+		`<a class="user" href="../../users/judge/index.html">Judge</a>`,
+	} {
+		if !strings.Contains(prediction, expected) {
+			t.Errorf("Prediction page should contain %s", expected)
+		}
+	}
+
+	user := readPage(t, dir, "users", "jbeshir")
+	if !strings.Contains(user, "mean Brier score of 0.040") {
+		t.Errorf("User page should include the user's Brier score")
+	}
+	if !strings.Contains(user, `href="../../predictions/1/index.html"`) {
+		t.Errorf("User page should list the user's predictions")
+	}
+
+	// Users who only commented or judged still get a page
+	readPage(t, dir, "users", "azt57@psu%5Bdot%5Dedu")
+	readPage(t, dir, "users", "judge")
+}
@@ -0,0 +1,97 @@
+package mirror
+
+import "html/template"
+
+var templates = template.Must(template.New("layout").Funcs(template.FuncMap{
+	"date":       formatDate,
+	"percentage": formatPercentage,
+	"userLink":   newUserLink,
+	"userPath":   userPath,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <title>{{.Title}} - PredictionBook mirror</title>
+</head>
+<body>
+<div id="header">
+  <a href="{{.Root}}index.html">Predictions</a>
+  <a href="{{.Root}}users/index.html">Users</a>
+  <span class="crawled">Mirrored from <a href="{{.BaseUrl}}">{{.BaseUrl}}</a> on {{date .Crawled}}</span>
+</div>
+<div id="content">
+{{template "content" .}}
+</div>
+</body>
+</html>
+{{define "userLink"}}{{if .Slug}}<a class="user" href="{{.Root}}{{userPath .Slug}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}{{end}}
+{{define "predictionList"}}<ul class="predictions">
+{{range .Predictions}}  <li class="prediction {{.Outcome}}">
+    <span class="title"><a href="{{$.Root}}predictions/{{.Id}}/index.html">{{.Title}}</a></span>
+    <span class="mean_confidence">{{percentage .MeanConfidence}} confidence</span>;
+    <span class="wagers_count">{{.WagerCount}} wagers</span>;
+    created by {{template "userLink" userLink $.Root .CreatorSlug .Creator}} on {{date .Created}};
+    known on {{date .Deadline}}{{if ne .Outcome.String "unknown"}}; judged <span class="outcome">{{.Outcome}}</span>{{end}}
+  </li>
+{{end}}</ul>{{end}}`))
+
+var listTemplate = template.Must(template.Must(templates.Clone()).Parse(`{{define "content"}}<h1>{{.Title}}</h1>
+{{template "predictionList" .}}
+<p class="pagination">
+{{if gt .Index 1}}  <a rel="prev" href="{{.Root}}predictions/page/{{.Prev}}/index.html">&lsaquo; Prev</a>
+{{end}}  Page {{.Index}} of {{.LastPage}}
+{{if lt .Index .LastPage}}  <a rel="next" href="{{.Root}}predictions/page/{{.Next}}/index.html">Next &rsaquo;</a>
+{{end}}</p>{{end}}`))
+
+var predictionTemplate = template.Must(template.Must(templates.Clone()).Parse(`{{define "content"}}{{with .Prediction}}<h1>{{.Title}}</h1>
+<p>
+  Created by {{template "userLink" userLink $.Root .CreatorSlug .Creator}} on {{date .Created}};
+  known on {{date .Deadline}}{{if ne .Outcome.String "unknown"}};
+  judged <span class="outcome">{{.Outcome}}</span>{{if not .Judged.IsZero}} on {{date .Judged}}{{end}}{{end}}.
+  <a href="{{$.BaseUrl}}/predictions/{{.Id}}">Original</a>
+</p>
+{{if .Details.Text}}<div class="details">
+  <p>{{.Details.Text}}</p>
+{{if .Details.Urls}}  <ul class="urls">
+{{range .Details.Urls}}    <li><a href="{{.}}">{{.}}</a></li>
+{{end}}  </ul>
+{{end}}</div>
+{{end}}{{end}}<h2>Responses</h2>
+<ul id="responses">
+{{range .Responses}}  <li class="response {{.Kind}}">
+    {{template "userLink" userLink $.Root .UserSlug .User}}
+    {{if .Kind.IsWager}}estimated <span class="confidence">{{percentage .Confidence}}</span>{{end}}
+    {{if .Comment}}{{if .Kind.IsWager}}and {{end}}said &ldquo;<span class="comment">{{.Comment}}</span>&rdquo;{{end}}
+    on {{date .Time}}
+  </li>
+{{end}}</ul>
+<h2>Judgement history</h2>
+<ul id="judgements">
+{{range .Prediction.Judgements}}  <li class="judgement">
+    {{template "userLink" userLink $.Root .UserSlug .User}}
+    judged this prediction <span class="outcome">{{.Outcome}}</span> on {{date .Time}}
+  </li>
+{{else}}  <li>Not yet judged.</li>
+{{end}}</ul>{{end}}`))
+
+var userTemplate = template.Must(template.Must(templates.Clone()).Parse(`{{define "content"}}{{with .User}}<h1>{{.Name}}</h1>
+<p>{{.Predictions}} predictions created; {{.Wagers}} wagers, of which {{.JudgedWagers}} judged{{if .JudgedWagers}}, with a mean Brier score of {{printf "%.3f" .BrierScore}}{{end}}.</p>
+<div class="statistics">
+  <h2>Calibration</h2>
+  <table>
+    <thead>
+      <tr><th>Confidence</th>{{range .Calibration}}<th>{{percentage .Confidence}}</th>{{end}}</tr>
+    </thead>
+    <tbody>
+      <tr><th>Accuracy</th>{{range .Calibration}}<td>{{if .Count}}{{percentage .Accuracy}}{{end}}</td>{{end}}</tr>
+      <tr><th>Sample Size</th>{{range .Calibration}}<td>{{.Count}}</td>{{end}}</tr>
+    </tbody>
+  </table>
+</div>
+{{end}}<h2>Predictions</h2>
+{{template "predictionList" .}}{{end}}`))
+
+var userListTemplate = template.Must(template.Must(templates.Clone()).Parse(`{{define "content"}}<h1>{{.Title}}</h1>
+<ul class="users">
+{{range .Users}}  <li><a href="{{$.Root}}{{userPath .Slug}}">{{.Name}}</a>: {{.Predictions}} predictions, {{.Wagers}} wagers</li>
+{{end}}</ul>{{end}}`))
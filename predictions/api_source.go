@@ -0,0 +1,225 @@
+package predictions
+
+import (
+	"context"
+	"errors"
+	"github.com/jbeshir/predictionbook-extractor/logging"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const apiPageSize = 1000
+
+// ApiSource retrieves predictions through PredictionBook's JSON API rather than
+// scraping HTML pages. It requires an API token, available from a user's settings page.
+type ApiSource struct {
+	baseUrl     string
+	apiToken    string
+	jsonFetcher JsonFetcher
+	progress    ProgressFunc
+	metrics     *Metrics
+	logger      logging.Logger
+}
+
+type JsonFetcher interface {
+	GetJson(ctx context.Context, url string, v interface{}) error
+}
+
+type apiPrediction struct {
+	Id             int64         `json:"id"`
+	Description    string        `json:"description"`
+	CreatorLabel   string        `json:"creator_label"`
+	CreatedAt      time.Time     `json:"created_at"`
+	Deadline       time.Time     `json:"deadline"`
+	MeanConfidence *float64      `json:"mean_confidence"`
+	WagerCount     *int64        `json:"wager_count"`
+	Outcome        *bool         `json:"outcome"`
+	Responses      []apiResponse `json:"responses"`
+}
+
+type apiResponse struct {
+	CreatedAt  time.Time `json:"created_at"`
+	UserLabel  string    `json:"user_label"`
+	Confidence *float64  `json:"confidence"`
+	Comment    string    `json:"comment"`
+}
+
+func NewApiSource(jsonFetcher JsonFetcher, baseUrl, apiToken string, logger logging.Logger) *ApiSource {
+	return &ApiSource{
+		baseUrl:     baseUrl,
+		apiToken:    apiToken,
+		jsonFetcher: jsonFetcher,
+		logger:      logging.OrDiscard(logger),
+	}
+}
+
+// SetProgressFunc sets a function to be called with progress through retrieving all
+// predictions or all their responses, or nil to stop reporting progress. The number of
+// API pages is not known in advance, so listing predictions has no total.
+func (s *ApiSource) SetProgressFunc(f ProgressFunc) {
+	s.progress = f
+}
+
+// SetMetrics counts the predictions and responses extracted with the given metrics, or stops if nil.
+func (s *ApiSource) SetMetrics(m *Metrics) {
+	s.metrics = m
+}
+
+func (s *ApiSource) Latest(ctx context.Context) (*PredictionSummary, error) {
+	latest, err := s.RetrievePredictionApiPage(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(latest) == 0 {
+		return nil, errors.New("no predictions found")
+	}
+
+	return latest[0], nil
+}
+
+func (s *ApiSource) AllPredictions(ctx context.Context) (predictions []*PredictionSummary, err error) {
+
+	return s.AllPredictionsSince(ctx, time.Time{})
+}
+
+func (s *ApiSource) AllPredictionsSince(ctx context.Context, t time.Time) (predictions []*PredictionSummary, err error) {
+
+	progress := newProgressTracker(s.progress, ListingPredictions, 0)
+	currentPage := int64(1)
+	for {
+		newPredictions, err := s.RetrievePredictionApiPage(ctx, currentPage)
+		if err != nil {
+			progress.failed()
+			s.logger.Debug("Failed to retrieve API page", logging.PageKey, currentPage, logging.ErrorKey, err)
+			return nil, err
+		}
+		s.logger.Debug("Retrieved API page", logging.PageKey, currentPage, "predictions", len(newPredictions))
+		if len(newPredictions) == 0 {
+			break
+		}
+
+		lastIncluded := len(newPredictions) - 1
+		for lastIncluded > -1 && newPredictions[lastIncluded].Created.Before(t) {
+			lastIncluded--
+		}
+
+		predictions = append(predictions, newPredictions[:lastIncluded+1]...)
+		progress.pageDone(0, lastIncluded+1, 0)
+
+		if lastIncluded < len(newPredictions)-1 || len(newPredictions) < apiPageSize {
+			break
+		}
+
+		currentPage++
+	}
+
+	// Sort and remove duplicates; they can appear due to predictions made during retrieval
+	predictions = sortPredictionSummaries(predictions)
+
+	return
+}
+
+func (s *ApiSource) AllPredictionResponses(ctx context.Context, predictions []*PredictionSummary) (summaries []*PredictionSummary, responses []*PredictionResponse, err error) {
+	return allPredictionResponses(ctx, predictions, s.RetrievePredictionResponses, s.progress, s.logger)
+}
+
+func (s *ApiSource) RetrievePredictionResponses(ctx context.Context, prediction int64) (summary *PredictionSummary, responses []*PredictionResponse, err error) {
+	var p apiPrediction
+	err = s.jsonFetcher.GetJson(ctx, s.apiUrl("/api/predictions/"+strconv.FormatInt(prediction, 10), nil), &p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, r := range p.Responses {
+		responses = append(responses, r.toResponse(prediction))
+	}
+
+	summary = p.toSummary()
+	s.metrics.observeSummaries("api", []*PredictionSummary{summary})
+	s.metrics.observeResponses("api", responses)
+	return summary, responses, nil
+}
+
+func (s *ApiSource) RetrievePredictionApiPage(ctx context.Context, index int64) (predictions []*PredictionSummary, err error) {
+	var ps []apiPrediction
+	err = s.jsonFetcher.GetJson(ctx, s.apiUrl("/api/predictions", url.Values{
+		"page":      {strconv.FormatInt(index, 10)},
+		"page_size": {strconv.Itoa(apiPageSize)},
+	}), &ps)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range ps {
+		predictions = append(predictions, ps[i].toSummary())
+	}
+	s.metrics.observeSummaries("api", predictions)
+
+	return predictions, nil
+}
+
+func (s *ApiSource) apiUrl(path string, query url.Values) string {
+	if query == nil {
+		query = make(url.Values)
+	}
+	query.Set("api_token", s.apiToken)
+
+	return s.baseUrl + path + "?" + query.Encode()
+}
+
+func (p *apiPrediction) toSummary() (prediction *PredictionSummary) {
+	prediction = new(PredictionSummary)
+	prediction.Id = p.Id
+	prediction.Title = p.Description
+	prediction.Creator = p.CreatorLabel
+	prediction.Created = p.CreatedAt
+	prediction.Deadline = p.Deadline
+
+	if p.Outcome == nil {
+		prediction.Outcome = Unknown
+	} else if *p.Outcome {
+		prediction.Outcome = Right
+	} else {
+		prediction.Outcome = Wrong
+	}
+
+	// The list endpoint doesn't include responses, so fall back to the precomputed values
+	if p.MeanConfidence != nil {
+		prediction.MeanConfidence = *p.MeanConfidence / 100
+	}
+	if p.WagerCount != nil {
+		prediction.WagerCount = *p.WagerCount
+	}
+
+	if p.Responses != nil {
+		var responses []*PredictionResponse
+		for _, r := range p.Responses {
+			responses = append(responses, r.toResponse(p.Id))
+		}
+		prediction.WagerCount, prediction.MeanConfidence = wagerStatistics(responses)
+	}
+
+	return
+}
+
+func (r *apiResponse) toResponse(prediction int64) (response *PredictionResponse) {
+	response = new(PredictionResponse)
+	response.Prediction = prediction
+	response.Time = r.CreatedAt
+	response.User = r.UserLabel
+	response.Comment = r.Comment
+	response.CommentMarkdown = escapeMarkdown(r.Comment)
+	response.Urls = dedupeStrings(bareUrlPattern.FindAllString(r.Comment, -1))
+	response.Mentions = dedupeStrings(extractMentions(r.Comment))
+
+	if r.Confidence != nil {
+		response.Confidence = *r.Confidence / 100
+	} else {
+		response.Confidence = math.NaN()
+	}
+	response.Kind = ResponseKindFor(response.Confidence, r.Comment != "")
+
+	return
+}
@@ -0,0 +1,139 @@
+package predictions
+
+import (
+	"context"
+	"github.com/jbeshir/predictionbook-extractor/htmlfetcher"
+	"golang.org/x/time/rate"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testApiServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_token") != "token" {
+			t.Errorf("Incorrect API token passed, should be %s, was %s", "token", r.URL.Query().Get("api_token"))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/predictions":
+			if r.URL.Query().Get("page") != "1" {
+				w.Write([]byte("[]"))
+				return
+			}
+			http.ServeFile(w, r, filepath.Join("testdata", "test_api_predictions.json"))
+		case "/api/predictions/193436":
+			http.ServeFile(w, r, filepath.Join("testdata", "test_api_prediction.json"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func testApiSource(server *httptest.Server) *ApiSource {
+	fetcher := htmlfetcher.NewFetcher(rate.NewLimiter(rate.Inf, 1), 1, nil)
+	return NewApiSource(fetcher, server.URL, "token", nil)
+}
+
+func TestApiLatest(t *testing.T) {
+	t.Parallel()
+
+	server := testApiServer(t)
+	defer server.Close()
+
+	s := testApiSource(server)
+	summary, err := s.Latest(context.Background())
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+	if summary.Id != 193473 {
+		t.Errorf("Incorrect latest prediction ID; should be %d, was %d", 193473, summary.Id)
+	}
+	if summary.Creator != "jbeshir" {
+		t.Errorf("Incorrect latest prediction creator; should be %s, was %s", "jbeshir", summary.Creator)
+	}
+	if summary.Outcome != Unknown {
+		t.Errorf("Incorrect latest prediction outcome; should be %d, was %d", Unknown, summary.Outcome)
+	}
+	if math.Abs(summary.MeanConfidence-0.4) > 0.00001 {
+		t.Errorf("Incorrect latest prediction mean confidence, should be %g, was %g", 0.4, summary.MeanConfidence)
+	}
+	if summary.WagerCount != 3 {
+		t.Errorf("Incorrect latest prediction wager count, should be %d, was %d", 3, summary.WagerCount)
+	}
+}
+
+func TestApiAllPredictionsSince(t *testing.T) {
+	t.Parallel()
+
+	server := testApiServer(t)
+	defer server.Close()
+
+	s := testApiSource(server)
+	summaries, err := s.AllPredictionsSince(context.Background(), time.Unix(1539214517, 0))
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("Retrieved incorrect number of predictions; should be %d, was %d", 2, len(summaries))
+	}
+	if summaries[0].Id != 193469 {
+		t.Errorf("First prediction had wrong ID; should be %d, was %d", 193469, summaries[0].Id)
+	}
+	if summaries[0].Outcome != Wrong {
+		t.Errorf("First prediction had wrong outcome; should be %d, was %d", Wrong, summaries[0].Outcome)
+	}
+}
+
+func TestApiRetrievePredictionResponses(t *testing.T) {
+	t.Parallel()
+
+	server := testApiServer(t)
+	defer server.Close()
+
+	s := testApiSource(server)
+	summary, responses, err := s.RetrievePredictionResponses(context.Background(), 193436)
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+	if len(responses) != 4 {
+		t.Fatalf("Incorrect number of responses; should be %d, was %d", 4, len(responses))
+	}
+	if responses[1].Time.Unix() != 1539248198 {
+		t.Errorf("Second response had incorrect time, should be %d, was %d", 1539248198, responses[1].Time.Unix())
+	}
+	if !math.IsNaN(responses[2].Confidence) {
+		t.Errorf("Third response had incorrect confidence, should be NaN, was %g", responses[2].Confidence)
+	}
+	if math.Abs(responses[3].Confidence-0.15) > 0.00001 {
+		t.Errorf("Fourth response had incorrect confidence, should be %g, was %g", 0.15, responses[3].Confidence)
+	}
+
+	if summary.Outcome != Right {
+		t.Errorf("Returned prediction summary had wrong outcome, should be %d, was %d", Right, summary.Outcome)
+	}
+	if summary.WagerCount != 3 {
+		t.Errorf("Returned prediction summary had wrong wager count, should be %d, was %d", 3, summary.WagerCount)
+	}
+	if math.Abs(summary.MeanConfidence-0.25) > 0.00001 {
+		t.Errorf("Returned prediction summary had wrong mean confidence, should be %g, was %g", 0.25, summary.MeanConfidence)
+	}
+}
+
+func TestApiRetrievePredictionResponsesError(t *testing.T) {
+	t.Parallel()
+
+	server := testApiServer(t)
+	defer server.Close()
+
+	s := testApiSource(server)
+	_, _, err := s.RetrievePredictionResponses(context.Background(), 1)
+	if err == nil {
+		t.Errorf("Error should have been returned for missing prediction")
+	}
+}
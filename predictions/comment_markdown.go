@@ -0,0 +1,149 @@
+package predictions
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"regexp"
+	"strings"
+)
+
+var mentionPattern = regexp.MustCompile(`(?:^|[\s(\[,;:])@([\w.\-]*\w)`)
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+	`>`, `\>`,
+)
+
+var excessNewlines = regexp.MustCompile(`\n{3,}`)
+
+// commentMarkdown converts comment HTML into Markdown, keeping only formatting,
+// line breaks and links with a safe scheme. Anything else is reduced to its text.
+func commentMarkdown(commentNode *html.Node) string {
+	var b strings.Builder
+	for c := commentNode.FirstChild; c != nil; c = c.NextSibling {
+		writeMarkdown(&b, c)
+	}
+
+	md := strings.TrimSpace(b.String())
+	return excessNewlines.ReplaceAllString(md, "\n\n")
+}
+
+func writeMarkdown(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(escapeMarkdown(collapseWhitespace(n.Data)))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Script, atom.Style:
+	case atom.Br:
+		b.WriteString("  \n")
+	case atom.P, atom.Div:
+		b.WriteString("\n\n")
+		writeMarkdownChildren(b, n)
+		b.WriteString("\n\n")
+	case atom.Em, atom.I:
+		writeMarkdownWrapped(b, n, "*")
+	case atom.Strong, atom.B:
+		writeMarkdownWrapped(b, n, "**")
+	case atom.Code:
+		b.WriteString("`" + strings.Replace(nodeText(n), "`", "'", -1) + "`")
+	case atom.A:
+		href := safeHref(n)
+		var text strings.Builder
+		writeMarkdownChildren(&text, n)
+		if href == "" {
+			b.WriteString(text.String())
+		} else if text.Len() == 0 {
+			b.WriteString("<" + href + ">")
+		} else {
+			b.WriteString("[" + text.String() + "](" + href + ")")
+		}
+	default:
+		writeMarkdownChildren(b, n)
+	}
+}
+
+func writeMarkdownChildren(b *strings.Builder, n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeMarkdown(b, c)
+	}
+}
+
+func writeMarkdownWrapped(b *strings.Builder, n *html.Node, marker string) {
+	var inner strings.Builder
+	writeMarkdownChildren(&inner, n)
+	if strings.TrimSpace(inner.String()) == "" {
+		b.WriteString(inner.String())
+		return
+	}
+	b.WriteString(marker + inner.String() + marker)
+}
+
+func safeHref(n *html.Node) string {
+	for _, attr := range n.Attr {
+		if attr.Key != "href" {
+			continue
+		}
+
+		href := strings.TrimSpace(attr.Val)
+		lower := strings.ToLower(href)
+		if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") ||
+			strings.HasPrefix(lower, "mailto:") || (strings.HasPrefix(href, "/") && !strings.HasPrefix(href, "//")) {
+			return strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(href)
+		}
+	}
+
+	return ""
+}
+
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(nodeText(c))
+	}
+	return b.String()
+}
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func collapseWhitespace(s string) string {
+	var b strings.Builder
+	lastSpace := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			if !lastSpace {
+				b.WriteRune(' ')
+			}
+			lastSpace = true
+			continue
+		}
+		b.WriteRune(r)
+		lastSpace = false
+	}
+	return b.String()
+}
+
+func extractMentions(text string) (mentions []string) {
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		mentions = append(mentions, match[1])
+	}
+
+	return
+}
@@ -0,0 +1,66 @@
+package predictions
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"regexp"
+	"strings"
+)
+
+type PredictionDetails struct {
+	Text string
+	Urls []string
+}
+
+var bareUrlPattern = regexp.MustCompile(`https?://[^\s<>"]+[^\s<>".,;:!?)\]]`)
+
+func ExtractPredictionDetails(responsePageNode *html.Node) (details PredictionDetails) {
+	responsePage := goquery.NewDocumentFromNode(responsePageNode).Selection
+
+	// Details are whatever the creator added between the title and the responses,
+	// skipping the paragraph holding creation, deadline and judgement information.
+	var paragraphs []string
+	var urls []string
+	responsePage.Find("#content > h1").NextUntil("#responses").Each(func(i int, s *goquery.Selection) {
+		if s.Find(".date").Length() > 0 || s.Find(".notice").Length() > 0 {
+			return
+		}
+
+		text := strings.Join(strings.Fields(s.Text()), " ")
+		if text == "" {
+			return
+		}
+		paragraphs = append(paragraphs, text)
+
+		urls = append(urls, extractLinkUrls(s)...)
+		urls = append(urls, bareUrlPattern.FindAllString(text, -1)...)
+	})
+
+	details.Text = strings.Join(paragraphs, "\n")
+	details.Urls = dedupeStrings(urls)
+
+	return
+}
+
+func extractLinkUrls(selection *goquery.Selection) (urls []string) {
+	selection.Find("a[href]").Each(func(i int, link *goquery.Selection) {
+		href, _ := link.Attr("href")
+		if strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") {
+			urls = append(urls, href)
+		}
+	})
+
+	return
+}
+
+func dedupeStrings(strs []string) (deduped []string) {
+	seen := make(map[string]bool)
+	for _, s := range strs {
+		if !seen[s] {
+			seen[s] = true
+			deduped = append(deduped, s)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,106 @@
+package predictions
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ActivityKind int64
+
+const (
+	PredictionCreated ActivityKind = iota
+	PredictionJudged
+	ResponseMade
+)
+
+func (k ActivityKind) String() string {
+	switch k {
+	case PredictionCreated:
+		return "created"
+	case PredictionJudged:
+		return "judged"
+	case ResponseMade:
+		return "response"
+	default:
+		return "unknown"
+	}
+}
+
+// ActivityEvent is one item of recent activity shown on the happenstance page.
+// Outcome is only set for judgements, and Response only for responses.
+type ActivityEvent struct {
+	Kind       ActivityKind
+	Prediction int64
+	Title      string
+	User       string
+	UserSlug   string
+	Time       time.Time
+	Outcome    Outcome
+	Response   *PredictionResponse
+}
+
+func ExtractHappenstance(happenstancePageNode *html.Node) (predictions []*PredictionSummary, events []*ActivityEvent) {
+	page := goquery.NewDocumentFromNode(happenstancePageNode)
+
+	page.Find("#recent_predictions .prediction").Each(func(i int, predictionSelector *goquery.Selection) {
+		prediction := ExtractPredictionSummary(predictionSelector.Nodes[0])
+		predictions = append(predictions, prediction)
+
+		events = append(events, &ActivityEvent{
+			Kind:       PredictionCreated,
+			Prediction: prediction.Id,
+			Title:      prediction.Title,
+			User:       prediction.Creator,
+			UserSlug:   prediction.CreatorSlug,
+			Time:       prediction.Created,
+		})
+	})
+
+	page.Find("#recent_judgements .judgement").Each(func(i int, judgementSelector *goquery.Selection) {
+		title, id := extractActivityPrediction(judgementSelector)
+		events = append(events, &ActivityEvent{
+			Kind:       PredictionJudged,
+			Prediction: id,
+			Title:      title,
+			User:       judgementSelector.Find(".user").First().Text(),
+			UserSlug:   extractUserSlug(judgementSelector.Find(".user")),
+			Time:       extractResponseTime(judgementSelector),
+			Outcome:    extractSummaryOutcome(judgementSelector),
+		})
+	})
+
+	page.Find("#recent_responses .response").Each(func(i int, responseSelector *goquery.Selection) {
+		title, id := extractActivityPrediction(responseSelector)
+		response := ExtractPredictionResponse(responseSelector.Nodes[0], id)
+		events = append(events, &ActivityEvent{
+			Kind:       ResponseMade,
+			Prediction: id,
+			Title:      title,
+			User:       response.User,
+			UserSlug:   response.UserSlug,
+			Time:       response.Time,
+			Response:   response,
+		})
+	})
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	return
+}
+
+func extractActivityPrediction(activitySelector *goquery.Selection) (title string, id int64) {
+	predictionLink := activitySelector.Find("a.prediction").First()
+	title = strings.TrimSpace(predictionLink.Text())
+
+	href, exists := predictionLink.Attr("href")
+	if exists {
+		id = predictionIdFromUrl(href)
+	}
+
+	return
+}
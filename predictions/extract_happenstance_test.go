@@ -0,0 +1,51 @@
+package predictions
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExtractHappenstance(t *testing.T) {
+	t.Parallel()
+
+	rootNode := testHtmlLoad(t, "test_happenstance.html")
+	predictions, events := ExtractHappenstance(rootNode)
+
+	if len(predictions) != 2 {
+		t.Fatalf("Incorrect number of predictions, should be %d, was %d", 2, len(predictions))
+	}
+	if predictions[0].Id != 193480 {
+		t.Errorf("Incorrect first prediction ID, should be %d, was %d", 193480, predictions[0].Id)
+	}
+
+	if len(events) != 6 {
+		t.Fatalf("Incorrect number of events, should be %d, was %d", 6, len(events))
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Time.Before(events[i-1].Time) {
+			t.Errorf("Events should be in time order, but event %d was before event %d", i, i-1)
+		}
+	}
+
+	judgement := events[2]
+	if judgement.Kind != PredictionJudged {
+		t.Fatalf("Incorrect third event kind, should be %s, was %s", PredictionJudged, judgement.Kind)
+	}
+	if judgement.Prediction != 193436 || judgement.Outcome != Wrong || judgement.UserSlug != "Cato" {
+		t.Errorf("Incorrect judgement event, should be 193436 judged wrong by Cato, was %+v", judgement)
+	}
+
+	response := events[4]
+	if response.Kind != ResponseMade {
+		t.Fatalf("Incorrect fifth event kind, should be %s, was %s", ResponseMade, response.Kind)
+	}
+	if response.Prediction != 193436 || response.Response.Prediction != 193436 {
+		t.Errorf("Incorrect response event prediction, should be %d, was %d", 193436, response.Prediction)
+	}
+	if response.Response.Kind != WagerAndComment || math.Abs(response.Response.Confidence-0.15) > 0.00001 {
+		t.Errorf("Incorrect response event response, should be a 15%% wager with comment, was %+v", response.Response)
+	}
+	if response.Title != "Convincing evidence will prove that Amazon, Apple, etc.'s chips were compromised." {
+		t.Errorf("Incorrect response event title, was %s", response.Title)
+	}
+}
@@ -0,0 +1,37 @@
+package predictions
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"time"
+)
+
+type PredictionJudgement struct {
+	Prediction int64
+	Time       time.Time
+	User       string
+	UserSlug   string
+	Outcome    Outcome
+}
+
+// ExtractPredictionJudgements extracts the judgement history listed among a prediction page's
+// responses, oldest first.
+func ExtractPredictionJudgements(responsePageNode *html.Node, prediction int64) (judgements []*PredictionJudgement) {
+	responsePage := goquery.NewDocumentFromNode(responsePageNode)
+
+	responsePage.Find("#responses .change").Each(func(i int, changeSelector *goquery.Selection) {
+		if changeSelector.Find(".outcome").Length() == 0 {
+			return
+		}
+
+		judgements = append(judgements, &PredictionJudgement{
+			Prediction: prediction,
+			Time:       extractResponseTime(changeSelector),
+			User:       changeSelector.Find(".user").First().Text(),
+			UserSlug:   extractUserSlug(changeSelector.Find(".user")),
+			Outcome:    extractSummaryOutcome(changeSelector),
+		})
+	})
+
+	return
+}
@@ -0,0 +1,26 @@
+package predictions
+
+import "testing"
+
+func TestExtractPredictionJudgements(t *testing.T) {
+	t.Parallel()
+
+	rootNode := testHtmlLoad(t, "test_responses.html")
+	judgements := ExtractPredictionJudgements(rootNode, 193436)
+
+	if len(judgements) != 1 {
+		t.Fatalf("Incorrect number of judgements, should be %d, was %d", 1, len(judgements))
+	}
+	if judgements[0].Prediction != 193436 {
+		t.Errorf("Incorrect judgement prediction ID, should be %d, was %d", 193436, judgements[0].Prediction)
+	}
+	if judgements[0].Outcome != Wrong {
+		t.Errorf("Incorrect judgement outcome, should be %d, was %d", Wrong, judgements[0].Outcome)
+	}
+	if judgements[0].UserSlug != "Cato" {
+		t.Errorf("Incorrect judgement user slug, should be %s, was %s", "Cato", judgements[0].UserSlug)
+	}
+	if judgements[0].Time.Unix() != 1549849586 {
+		t.Errorf("Incorrect judgement time, should be %d, was %d", 1549849586, judgements[0].Time.Unix())
+	}
+}
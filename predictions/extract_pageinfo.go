@@ -3,6 +3,7 @@ package predictions
 import (
 	"github.com/PuerkitoBio/goquery"
 	"golang.org/x/net/html"
+	"net/url"
 	"strconv"
 	"strings"
 )
@@ -20,12 +21,17 @@ func ExtractPredictionListPageInfo(rootNode *html.Node, index int64) (pageInfo *
 
 	lastPageHref, exists := page.Find("nav.pagination .last a").Attr("href")
 	if exists {
+		// The main list uses paths for page numbers, while user pages use a query parameter
+		var lastPageStr string
 		if strings.HasPrefix(lastPageHref, "/predictions/page/") {
-			lastPageStr := lastPageHref[len("/predictions/page/"):]
-			lastPage, err := strconv.ParseInt(lastPageStr, 10, 64)
-			if err == nil {
-				pageInfo.LastPage = lastPage
-			}
+			lastPageStr = lastPageHref[len("/predictions/page/"):]
+		} else if lastPageUrl, err := url.Parse(lastPageHref); err == nil {
+			lastPageStr = lastPageUrl.Query().Get("page")
+		}
+
+		lastPage, err := strconv.ParseInt(lastPageStr, 10, 64)
+		if err == nil {
+			pageInfo.LastPage = lastPage
 		}
 	} else {
 		pageInfo.LastPage = index
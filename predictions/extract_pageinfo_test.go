@@ -48,3 +48,14 @@ func TestExtractPageInfoLast(t *testing.T) {
 		t.Errorf("Incorrect page index; should be %d, was %d", 287, pageInfo.LastPage)
 	}
 }
+
+func TestExtractPageInfoUser(t *testing.T) {
+	t.Parallel()
+
+	rootNode := testHtmlLoad(t, "test_user.html")
+
+	pageInfo := ExtractPredictionListPageInfo(rootNode, 1)
+	if pageInfo.LastPage != 3 {
+		t.Errorf("Incorrect last page; should be %d, was %d", 3, pageInfo.LastPage)
+	}
+}
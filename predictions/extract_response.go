@@ -1,6 +1,7 @@
 package predictions
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/PuerkitoBio/goquery"
 	"golang.org/x/net/html"
@@ -10,11 +11,84 @@ import (
 )
 
 type PredictionResponse struct {
-	Prediction int64
-	Time       time.Time
-	User       string
-	Confidence float64
-	Comment    string
+	Prediction      int64
+	Time            time.Time
+	User            string
+	UserSlug        string
+	Confidence      float64
+	Kind            ResponseKind
+	Comment         string
+	CommentMarkdown string
+	Urls            []string
+	Mentions        []string
+}
+
+type ResponseKind int64
+
+const (
+	// UnknownKind is a response with neither a confidence nor a comment, or a kind which
+	// wasn't recorded.
+	UnknownKind ResponseKind = iota
+	WagerOnly
+	CommentOnly
+	WagerAndComment
+)
+
+func (k ResponseKind) String() string {
+	switch k {
+	case WagerOnly:
+		return "wager"
+	case CommentOnly:
+		return "comment"
+	case WagerAndComment:
+		return "wager_and_comment"
+	default:
+		return "unknown"
+	}
+}
+
+// legacyResponseKinds maps the integer codes stored by earlier versions to kinds.
+var legacyResponseKinds = []ResponseKind{WagerOnly, CommentOnly, WagerAndComment}
+
+// MarshalText encodes the kind by name, so stored datasets and API responses don't
+// depend on the order of the constants.
+func (k ResponseKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *ResponseKind) UnmarshalText(text []byte) error {
+	for _, candidate := range []ResponseKind{UnknownKind, WagerOnly, CommentOnly, WagerAndComment} {
+		if string(text) == candidate.String() {
+			*k = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid response kind %q", text)
+}
+
+// UnmarshalJSON accepts both names and the integer codes stored by earlier versions.
+func (k *ResponseKind) UnmarshalJSON(data []byte) error {
+	var code int64
+	if json.Unmarshal(data, &code) == nil {
+		if code < 0 || code >= int64(len(legacyResponseKinds)) {
+			return fmt.Errorf("invalid response kind %d", code)
+		}
+		*k = legacyResponseKinds[code]
+		return nil
+	}
+
+	var text string
+	err := json.Unmarshal(data, &text)
+	if err != nil {
+		return err
+	}
+	return k.UnmarshalText([]byte(text))
+}
+
+// IsWager reports whether the response assigned a confidence, and so should count
+// towards a prediction's wager count and mean confidence.
+func (k ResponseKind) IsWager() bool {
+	return k == WagerOnly || k == WagerAndComment
 }
 
 func ExtractPredictionResponse(responseNode *html.Node, prediction int64) (response *PredictionResponse) {
@@ -25,8 +99,10 @@ func ExtractPredictionResponse(responseNode *html.Node, prediction int64) (respo
 
 	response.Time = extractResponseTime(responseSelector)
 	response.User = responseSelector.Find(".user").Text()
-	response.Comment = responseSelector.Find(".comment").Text()
+	response.UserSlug = extractUserSlug(responseSelector.Find(".user"))
 	response.Confidence = extractResponseConfidence(responseSelector)
+	extractResponseComment(responseSelector, response)
+	response.Kind = ResponseKindFor(response.Confidence, responseSelector.Find(".comment").Length() > 0)
 
 	return
 }
@@ -43,6 +119,54 @@ func extractResponseTime(responseSelector *goquery.Selection) (t time.Time) {
 	return
 }
 
+func extractUserSlug(userSelector *goquery.Selection) (slug string) {
+	userHref, exists := userSelector.First().Attr("href")
+	if exists && strings.HasPrefix(userHref, "/users/") {
+		slug = userHref[len("/users/"):]
+	}
+
+	return
+}
+
+// ResponseKindFor classifies a response by whether it assigned a confidence and whether
+// it had a comment.
+func ResponseKindFor(confidence float64, hasComment bool) ResponseKind {
+	if math.IsNaN(confidence) && !hasComment {
+		return UnknownKind
+	} else if math.IsNaN(confidence) {
+		return CommentOnly
+	} else if hasComment {
+		return WagerAndComment
+	} else {
+		return WagerOnly
+	}
+}
+
+func extractResponseComment(responseSelector *goquery.Selection, response *PredictionResponse) {
+	commentSelector := responseSelector.Find(".comment")
+	response.Comment = commentSelector.Text()
+	if len(commentSelector.Nodes) == 0 {
+		return
+	}
+
+	response.CommentMarkdown = commentMarkdown(commentSelector.Nodes[0])
+
+	var urls []string
+	urls = append(urls, extractLinkUrls(commentSelector)...)
+	urls = append(urls, bareUrlPattern.FindAllString(response.Comment, -1)...)
+	response.Urls = dedupeStrings(urls)
+
+	// Mentions are either written inline as @user or linked to the user's page
+	mentions := extractMentions(response.Comment)
+	commentSelector.Find("a[href]").Each(func(i int, link *goquery.Selection) {
+		href, _ := link.Attr("href")
+		if strings.HasPrefix(href, "/users/") {
+			mentions = append(mentions, href[len("/users/"):])
+		}
+	})
+	response.Mentions = dedupeStrings(mentions)
+}
+
 func extractResponseConfidence(responseSelector *goquery.Selection) (confidence float64) {
 	confidenceStr := strings.TrimSpace(responseSelector.Find(".confidence").Text())
 	var confidencePercentage float64
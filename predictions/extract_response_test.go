@@ -4,6 +4,7 @@ import (
 	"github.com/PuerkitoBio/goquery"
 	"golang.org/x/net/html"
 	"math"
+	"strings"
 	"testing"
 )
 
@@ -33,6 +34,9 @@ func TestExtractResponseAssignmentOnly(t *testing.T) {
 	if response.User != "pranomostro" {
 		t.Errorf("Incorrect response user, should be %s, was %s", "pranomostro", response.User)
 	}
+	if response.UserSlug != "pranomostro" {
+		t.Errorf("Incorrect response user slug, should be %s, was %s", "pranomostro", response.UserSlug)
+	}
 	if response.Time.Unix() != 1539248198 {
 		t.Errorf("Incorrect response time, should be %d, was %d", 1539248198, response.Time.Unix())
 	}
@@ -42,6 +46,9 @@ func TestExtractResponseAssignmentOnly(t *testing.T) {
 	if response.Comment != "" {
 		t.Errorf("Incorrect response comment, should be empty string, was %s", response.Comment)
 	}
+	if response.Kind != WagerOnly {
+		t.Errorf("Incorrect response kind, should be %s, was %s", WagerOnly, response.Kind)
+	}
 }
 
 func TestExtractResponseCommentOnly(t *testing.T) {
@@ -53,6 +60,9 @@ func TestExtractResponseCommentOnly(t *testing.T) {
 	if !math.IsNaN(response.Confidence) {
 		t.Errorf("Incorrect response confidence, should be NaN, was %g", response.Confidence)
 	}
+	if response.Kind != CommentOnly {
+		t.Errorf("Incorrect response kind, should be %s, was %s", CommentOnly, response.Kind)
+	}
 	if response.Comment != "Compromised in general, or specifically compromised in the way described by the recent Bloomberg article?" {
 		t.Errorf("Incorrect response comment, should be '%s', was '%s'", "Compromised in general, or specifically compromised in the way described by the recent Bloomberg article?", response.Comment)
 	}
@@ -67,7 +77,83 @@ func TestExtractResponseCommentAndAssignment(t *testing.T) {
 	if math.Abs(response.Confidence-0.15) > 0.00001 {
 		t.Errorf("Incorrect response confidence, should be %g, was %g", 0.15, response.Confidence)
 	}
+	if response.Kind != WagerAndComment {
+		t.Errorf("Incorrect response kind, should be %s, was %s", WagerAndComment, response.Kind)
+	}
 	if response.Comment != "I’m assuming you mean specifically compromised in the way described by the Bloomberg article" {
 		t.Errorf("Incorrect response comment, should be '%s', was '%s'", "I’m assuming you mean specifically compromised in the way described by the Bloomberg article", response.Comment)
 	}
 }
+
+func TestExtractResponseCommentLinks(t *testing.T) {
+	t.Parallel()
+
+	responses := testResponsesLoad(t)
+	response := ExtractPredictionResponse(responses[2], 193436)
+
+	expectedUrl := "https://www.bloomberg.com/news/features/2018-10-04/the-big-hack-how-china-used-a-tiny-chip-to-infiltrate-america-s-top-companies"
+	if len(response.Urls) != 1 || response.Urls[0] != expectedUrl {
+		t.Errorf("Incorrect response URLs, should be [%s], was %v", expectedUrl, response.Urls)
+	}
+
+	expectedMarkdown := "Compromised in general, or specifically compromised in the way described by the recent [Bloomberg article](" + expectedUrl + ")?"
+	if response.CommentMarkdown != expectedMarkdown {
+		t.Errorf("Incorrect response comment markdown, should be '%s', was '%s'", expectedMarkdown, response.CommentMarkdown)
+	}
+	if len(response.Mentions) != 0 {
+		t.Errorf("Incorrect response mentions, should be empty, was %v", response.Mentions)
+	}
+}
+
+func TestExtractResponseCommentFormatting(t *testing.T) {
+	t.Parallel()
+
+	rootNode, err := html.Parse(strings.NewReader(`<ul><li class="response">
+		<a class="user" href="/users/jbeshir">jbeshir</a>
+		said “<span class="comment">@MTGandP see <a href="/users/sdr">sdr</a>&#8217;s <em>comment</em><br>
+		and https://example.org/evidence, not <a href="javascript:alert(1)">this</a> *literally*</span>”
+		<span title="2018-10-12 03:55:08 UTC" class="date">on 2018-10-12</span>
+	</li></ul>`))
+	if err != nil {
+		t.Fatalf("Couldn't parse test response: %s", err)
+	}
+	responseNode := goquery.NewDocumentFromNode(rootNode).Find(".response").Nodes[0]
+	response := ExtractPredictionResponse(responseNode, 1)
+
+	expectedMarkdown := "@MTGandP see [sdr](/users/sdr)’s *comment*  \n and https://example.org/evidence, not this \\*literally\\*"
+	if response.CommentMarkdown != expectedMarkdown {
+		t.Errorf("Incorrect response comment markdown, should be '%s', was '%s'", expectedMarkdown, response.CommentMarkdown)
+	}
+	if len(response.Urls) != 1 || response.Urls[0] != "https://example.org/evidence" {
+		t.Errorf("Incorrect response URLs, should be [%s], was %v", "https://example.org/evidence", response.Urls)
+	}
+	if len(response.Mentions) != 2 || response.Mentions[0] != "MTGandP" || response.Mentions[1] != "sdr" {
+		t.Errorf("Incorrect response mentions, should be [MTGandP sdr], was %v", response.Mentions)
+	}
+}
+
+func TestResponseKindFor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		confidence float64
+		hasComment bool
+		kind       ResponseKind
+	}{
+		{0.25, false, WagerOnly},
+		{math.NaN(), true, CommentOnly},
+		{0.25, true, WagerAndComment},
+		{math.NaN(), false, UnknownKind},
+	}
+	for _, test := range tests {
+		kind := ResponseKindFor(test.confidence, test.hasComment)
+		if kind != test.kind {
+			t.Errorf("Incorrect kind for confidence %g and comment %t; should be %s, was %s", test.confidence, test.hasComment, test.kind, kind)
+		}
+	}
+
+	var zero ResponseKind
+	if zero != UnknownKind {
+		t.Errorf("Zero value should be %s, was %s", UnknownKind, zero)
+	}
+}
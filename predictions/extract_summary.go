@@ -1,9 +1,11 @@
 package predictions
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/PuerkitoBio/goquery"
 	"golang.org/x/net/html"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -13,11 +15,21 @@ type PredictionSummary struct {
 	Id             int64
 	Title          string
 	Creator        string
+	CreatorSlug    string
 	Created        time.Time
 	Deadline       time.Time
+	Judged         time.Time
 	MeanConfidence float64
 	WagerCount     int64
 	Outcome        Outcome
+	Details        PredictionDetails
+	Judgements     []*PredictionJudgement
+
+	// Topics are assigned after extraction by the classify package, not by the site.
+	Topics []string
+	// DuplicateCluster is the lowest id among the prediction's near-duplicates, as assigned
+	// by the dedupe package, or zero if it has none.
+	DuplicateCluster int64
 }
 
 type Outcome int64
@@ -28,6 +40,52 @@ const (
 	Wrong
 )
 
+func (o Outcome) String() string {
+	switch o {
+	case Right:
+		return "right"
+	case Wrong:
+		return "wrong"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the outcome by name, so stored datasets and API responses don't
+// depend on the order of the constants.
+func (o Outcome) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o *Outcome) UnmarshalText(text []byte) error {
+	for _, candidate := range []Outcome{Unknown, Right, Wrong} {
+		if string(text) == candidate.String() {
+			*o = candidate
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid outcome %q", text)
+}
+
+// UnmarshalJSON accepts both names and the integer codes stored by earlier versions.
+func (o *Outcome) UnmarshalJSON(data []byte) error {
+	var code int64
+	if json.Unmarshal(data, &code) == nil {
+		if code < int64(Unknown) || code > int64(Wrong) {
+			return fmt.Errorf("invalid outcome %d", code)
+		}
+		*o = Outcome(code)
+		return nil
+	}
+
+	var text string
+	err := json.Unmarshal(data, &text)
+	if err != nil {
+		return err
+	}
+	return o.UnmarshalText([]byte(text))
+}
+
 func ExtractPredictionSummary(predictionNode *html.Node) (prediction *PredictionSummary) {
 	prediction = new(PredictionSummary)
 
@@ -35,8 +93,10 @@ func ExtractPredictionSummary(predictionNode *html.Node) (prediction *Prediction
 
 	prediction.Title, prediction.Id = extractSummaryTitleAndId(predictionSelector)
 	prediction.Creator = extractSummaryCreator(predictionSelector)
+	prediction.CreatorSlug = extractUserSlug(predictionSelector.Find(".creator"))
 	prediction.Created = extractSummaryCreated(predictionSelector)
 	prediction.Deadline = extractSummaryDeadline(predictionSelector)
+	prediction.Judged = extractSummaryJudged(predictionSelector.Find(".judgement"))
 	prediction.MeanConfidence = extractSummaryMeanConfidence(predictionSelector)
 	prediction.WagerCount = extractSummaryWagerCount(predictionSelector)
 	prediction.Outcome = extractSummaryOutcome(predictionSelector)
@@ -51,13 +111,19 @@ func extractSummaryTitleAndId(predictionSelector *goquery.Selection) (title stri
 
 	predictionUrl, exists := titleLink.Attr("href")
 	if exists {
-		predictionUrlParts := strings.Split(predictionUrl, "/")
-		if len(predictionUrlParts) > 0 {
-			predictionIdStr := predictionUrlParts[len(predictionUrlParts)-1]
-			parsedId, err := strconv.ParseInt(predictionIdStr, 10, 64)
-			if err == nil {
-				id = parsedId
-			}
+		id = predictionIdFromUrl(predictionUrl)
+	}
+
+	return
+}
+
+func predictionIdFromUrl(predictionUrl string) (id int64) {
+	predictionUrlParts := strings.Split(predictionUrl, "/")
+	if len(predictionUrlParts) > 0 {
+		predictionIdStr := predictionUrlParts[len(predictionUrlParts)-1]
+		parsedId, err := strconv.ParseInt(predictionIdStr, 10, 64)
+		if err == nil {
+			id = parsedId
 		}
 	}
 
@@ -100,7 +166,21 @@ func extractSummaryDeadline(predictionSelector *goquery.Selection) (deadline tim
 	return
 }
 
+func extractSummaryJudged(judgementSelector *goquery.Selection) (judged time.Time) {
+	judgedStr, exists := judgementSelector.Find(".date").First().Attr("title")
+	if exists {
+		t, err := time.Parse("2006-01-02 15:04:05 MST", judgedStr)
+		if err == nil {
+			judged = t
+		}
+	}
+
+	return
+}
+
+// extractSummaryMeanConfidence returns NaN if the list page doesn't show a mean confidence.
 func extractSummaryMeanConfidence(predictionSelector *goquery.Selection) (meanConfidence float64) {
+	meanConfidence = math.NaN()
 	confidenceStr := strings.TrimSpace(predictionSelector.Find(".mean_confidence").Text())
 	var confidencePercentage float64
 	_, err := fmt.Sscanf(confidenceStr, "%f%% confidence", &confidencePercentage)
@@ -3,7 +3,6 @@ package predictions
 import (
 	"github.com/PuerkitoBio/goquery"
 	"golang.org/x/net/html"
-	"math"
 	"strings"
 	"time"
 )
@@ -14,22 +13,34 @@ func ExtractPredictionSummaryResponsePage(id int64, responsePageNode *html.Node)
 	prediction = new(PredictionSummary)
 	prediction.Id = id
 	prediction.Title = strings.TrimSpace(responsePage.Find("h1").Text())
-	prediction.WagerCount = int64(len(responsePage.Find(".response").Nodes))
 	prediction.Creator = responsePage.Find("#content > p > a.user").Text()
+	prediction.CreatorSlug = extractUserSlug(responsePage.Find("#content > p > a.user"))
 	prediction.Created = extractSummaryResponsePageCreated(responsePage)
 	prediction.Deadline = extractSummaryResponsePageDeadline(responsePage)
+	prediction.Judged = extractSummaryJudged(responsePage.Find("#content > p > .judgement"))
 	prediction.Outcome = extractSummaryOutcome(responsePage)
+	prediction.Details = ExtractPredictionDetails(responsePageNode)
+	prediction.Judgements = ExtractPredictionJudgements(responsePageNode, id)
 
-	sumConfidence := 0.0
-	totalAssignments := 0
+	var responses []*PredictionResponse
 	for _, respNode := range responsePage.Find(".response").Nodes {
-		resp := ExtractPredictionResponse(respNode, id)
-		if !math.IsNaN(resp.Confidence) {
+		responses = append(responses, ExtractPredictionResponse(respNode, id))
+	}
+	prediction.WagerCount, prediction.MeanConfidence = wagerStatistics(responses)
+	return
+}
+
+// wagerStatistics counts the responses which assigned a confidence and averages them,
+// ignoring comment-only responses.
+func wagerStatistics(responses []*PredictionResponse) (wagerCount int64, meanConfidence float64) {
+	sumConfidence := 0.0
+	for _, resp := range responses {
+		if resp.Kind.IsWager() {
 			sumConfidence += resp.Confidence
-			totalAssignments++
+			wagerCount++
 		}
 	}
-	prediction.MeanConfidence = sumConfidence / float64(totalAssignments)
+	meanConfidence = sumConfidence / float64(wagerCount)
 	return
 }
 
@@ -55,4 +66,4 @@ func extractSummaryResponsePageDeadline(pageSelector *goquery.Selection) (deadli
 	}
 
 	return
-}
\ No newline at end of file
+}
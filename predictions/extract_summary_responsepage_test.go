@@ -26,13 +26,42 @@ func TestExtractPredictionSummaryResponsePage(t *testing.T) {
 	if prediction.Created.Unix() != 1538836809 {
 		t.Errorf("Incorrect prediction created time, should be %d, was %d", 1548870174, prediction.Created.Unix())
 	}
+	if prediction.Judged.Unix() != 1548648304 {
+		t.Errorf("Incorrect prediction judged time, should be %d, was %d", 1548648304, prediction.Judged.Unix())
+	}
 	if prediction.Deadline.Unix() != 1554552000 {
 		t.Errorf("Incorrect prediction deadline, should be %d, was %d", 1541088000, prediction.Deadline.Unix())
 	}
 	if math.Abs(prediction.MeanConfidence-0.25857142857) > 0.00001 {
 		t.Errorf("Incorrect prediction mean confidence, should be %g, was %g", 0.25857142857, prediction.MeanConfidence)
 	}
-	if prediction.WagerCount != 8 {
-		t.Errorf("Incorrect prediction wager count, should be %d, was %d", 8, prediction.WagerCount)
+	if prediction.WagerCount != 7 {
+		t.Errorf("Incorrect prediction wager count, should be %d, was %d", 7, prediction.WagerCount)
+	}
+}
+
+func TestExtractPredictionSummaryResponsePageDetails(t *testing.T) {
+	t.Parallel()
+
+	rootNode := testHtmlLoad(t, "test_responses.html")
+	prediction := ExtractPredictionSummaryResponsePage(123, rootNode)
+
+	expectedText := "Per the Bloomberg report, the chips were added during manufacture.\n" +
+		"Judged right if either company admits it; see https://example.org/followup for context."
+	if prediction.Details.Text != expectedText {
+		t.Errorf("Incorrect prediction details text, should be '%s', was '%s'", expectedText, prediction.Details.Text)
+	}
+
+	expectedUrls := []string{
+		"https://www.bloomberg.com/news/features/2018-10-04/the-big-hack-how-china-used-a-tiny-chip-to-infiltrate-america-s-top-companies",
+		"https://example.org/followup",
+	}
+	if len(prediction.Details.Urls) != len(expectedUrls) {
+		t.Fatalf("Incorrect number of prediction details URLs, should be %d, was %d", len(expectedUrls), len(prediction.Details.Urls))
+	}
+	for i := range expectedUrls {
+		if prediction.Details.Urls[i] != expectedUrls[i] {
+			t.Errorf("Incorrect prediction details URL %d, should be %s, was %s", i, expectedUrls[i], prediction.Details.Urls[i])
+		}
 	}
 }
@@ -39,9 +39,15 @@ func TestExtractPredictionSummarySingleWager(t *testing.T) {
 	if prediction.Creator != "notsonewuser" {
 		t.Errorf("Incorrect prediction creator, should be %s, was %s", "notsonewuser", prediction.Creator)
 	}
+	if prediction.CreatorSlug != "notsonewuser" {
+		t.Errorf("Incorrect prediction creator slug, should be %s, was %s", "notsonewuser", prediction.CreatorSlug)
+	}
 	if prediction.Created.Unix() != 1539214517 {
 		t.Errorf("Incorrect prediction created time, should be %d, was %d", 1539214517, prediction.Created.Unix())
 	}
+	if prediction.Judged.Unix() != 1539929373 {
+		t.Errorf("Incorrect prediction judged time, should be %d, was %d", 1539929373, prediction.Judged.Unix())
+	}
 	if prediction.Deadline.Unix() != 1541088000 {
 		t.Errorf("Incorrect prediction deadline, should be %d, was %d", 1541088000, prediction.Deadline.Unix())
 	}
@@ -0,0 +1,99 @@
+package predictions
+
+import (
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"math"
+	"strings"
+	"time"
+)
+
+type UserProfile struct {
+	Slug            string
+	DisplayName     string
+	Joined          time.Time
+	PredictionCount int64
+	ResponseCount   int64
+	Statistics      []*CalibrationStatistic
+}
+
+// CalibrationStatistic is one column of the statistics table shown on list and profile pages;
+// the accuracy of predictions made at a given confidence.
+type CalibrationStatistic struct {
+	Confidence float64
+	Accuracy   float64
+	SampleSize int64
+}
+
+func ExtractUserProfile(slug string, userPageNode *html.Node) (profile *UserProfile) {
+	userPage := goquery.NewDocumentFromNode(userPageNode).Selection
+
+	profile = new(UserProfile)
+	profile.Slug = slug
+	profile.DisplayName = strings.TrimSpace(userPage.Find("#content > h1").First().Text())
+	profile.Joined = extractUserJoined(userPage)
+	profile.PredictionCount = extractUserCount(userPage.Find(".member .predictions_count"), "%d predictions")
+	profile.ResponseCount = extractUserCount(userPage.Find(".member .responses_count"), "%d responses")
+	profile.Statistics = ExtractCalibrationStatistics(userPageNode)
+
+	return
+}
+
+func ExtractCalibrationStatistics(pageNode *html.Node) (statistics []*CalibrationStatistic) {
+	table := goquery.NewDocumentFromNode(pageNode).Find(".statistics table").First()
+
+	table.Find("thead th").Each(func(i int, th *goquery.Selection) {
+		var confidencePercentage float64
+		_, err := fmt.Sscanf(strings.TrimSpace(th.Text()), "%f%%", &confidencePercentage)
+		if err != nil {
+			return
+		}
+
+		statistic := &CalibrationStatistic{
+			Confidence: confidencePercentage / 100,
+			Accuracy:   math.NaN(),
+		}
+
+		// Header cells line up with the row header followed by data cells in the body rows
+		table.Find("tbody tr").Each(func(j int, row *goquery.Selection) {
+			cell := strings.TrimSpace(row.Children().Eq(i).Text())
+			switch strings.TrimSpace(row.Find("th").First().Text()) {
+			case "Accuracy":
+				var accuracyPercentage float64
+				_, err := fmt.Sscanf(cell, "%f%%", &accuracyPercentage)
+				if err == nil {
+					statistic.Accuracy = accuracyPercentage / 100
+				}
+			case "Sample Size":
+				fmt.Sscanf(cell, "%d", &statistic.SampleSize)
+			}
+		})
+
+		statistics = append(statistics, statistic)
+	})
+
+	return
+}
+
+func extractUserJoined(userPage *goquery.Selection) (joined time.Time) {
+	joinedStr, exists := userPage.Find(".member .date").First().Attr("title")
+	if exists {
+		t, err := time.Parse("2006-01-02 15:04:05 MST", joinedStr)
+		if err == nil {
+			joined = t
+		}
+	}
+
+	return
+}
+
+func extractUserCount(countSelector *goquery.Selection, format string) (count int64) {
+	var i int64
+	_, err := fmt.Sscanf(strings.TrimSpace(countSelector.Text()), format, &i)
+	if err == nil {
+		count = i
+	}
+
+	return
+}
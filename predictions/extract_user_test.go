@@ -0,0 +1,52 @@
+package predictions
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExtractUserProfile(t *testing.T) {
+	t.Parallel()
+
+	rootNode := testHtmlLoad(t, "test_user.html")
+	profile := ExtractUserProfile("jbeshir", rootNode)
+
+	if profile.Slug != "jbeshir" {
+		t.Errorf("Incorrect user slug, should be %s, was %s", "jbeshir", profile.Slug)
+	}
+	if profile.DisplayName != "jbeshir" {
+		t.Errorf("Incorrect user display name, should be %s, was %s", "jbeshir", profile.DisplayName)
+	}
+	if profile.Joined.Unix() != 1368980431 {
+		t.Errorf("Incorrect user join date, should be %d, was %d", 1368980431, profile.Joined.Unix())
+	}
+	if profile.PredictionCount != 112 {
+		t.Errorf("Incorrect user prediction count, should be %d, was %d", 112, profile.PredictionCount)
+	}
+	if profile.ResponseCount != 431 {
+		t.Errorf("Incorrect user response count, should be %d, was %d", 431, profile.ResponseCount)
+	}
+}
+
+func TestExtractCalibrationStatistics(t *testing.T) {
+	t.Parallel()
+
+	rootNode := testHtmlLoad(t, "test_user.html")
+	statistics := ExtractCalibrationStatistics(rootNode)
+
+	if len(statistics) != 6 {
+		t.Fatalf("Incorrect number of statistics, should be %d, was %d", 6, len(statistics))
+	}
+	if math.Abs(statistics[2].Confidence-0.7) > 0.00001 {
+		t.Errorf("Incorrect third statistic confidence, should be %g, was %g", 0.7, statistics[2].Confidence)
+	}
+	if math.Abs(statistics[2].Accuracy-0.71) > 0.00001 {
+		t.Errorf("Incorrect third statistic accuracy, should be %g, was %g", 0.71, statistics[2].Accuracy)
+	}
+	if statistics[2].SampleSize != 14 {
+		t.Errorf("Incorrect third statistic sample size, should be %d, was %d", 14, statistics[2].SampleSize)
+	}
+	if math.Abs(statistics[5].Accuracy-1) > 0.00001 {
+		t.Errorf("Incorrect last statistic accuracy, should be %g, was %g", 1.0, statistics[5].Accuracy)
+	}
+}
@@ -0,0 +1,50 @@
+package predictions
+
+import (
+	"strings"
+	"time"
+)
+
+// Filter restricts predictions by their fields. Unset fields match anything.
+type Filter struct {
+	// Creator matches either the creator's slug or display name.
+	Creator string
+	// Outcome matches the name of the outcome, as given by Outcome.String.
+	Outcome        string
+	CreatedAfter   time.Time
+	CreatedBefore  time.Time
+	DeadlineAfter  time.Time
+	DeadlineBefore time.Time
+	// Text matches the title or details case-insensitively.
+	Text string
+}
+
+// Matches reports whether the prediction passes every filter which was set.
+func (f *Filter) Matches(p *PredictionSummary) bool {
+	if f.Creator != "" && f.Creator != p.CreatorSlug && f.Creator != p.Creator {
+		return false
+	}
+	if f.Outcome != "" && f.Outcome != p.Outcome.String() {
+		return false
+	}
+	if !f.CreatedAfter.IsZero() && p.Created.Before(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !p.Created.Before(f.CreatedBefore) {
+		return false
+	}
+	if !f.DeadlineAfter.IsZero() && p.Deadline.Before(f.DeadlineAfter) {
+		return false
+	}
+	if !f.DeadlineBefore.IsZero() && !p.Deadline.Before(f.DeadlineBefore) {
+		return false
+	}
+	if f.Text != "" {
+		text := strings.ToLower(f.Text)
+		if !strings.Contains(strings.ToLower(p.Title), text) && !strings.Contains(strings.ToLower(p.Details.Text), text) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,44 @@
+package predictions
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterMatches(t *testing.T) {
+	t.Parallel()
+
+	p := &PredictionSummary{
+		Title:       "Will it rain?",
+		Creator:     "Michael Dickens",
+		CreatorSlug: "MTGandP",
+		Created:     time.Unix(1000, 0),
+		Deadline:    time.Unix(5000, 0),
+		Outcome:     Right,
+		Details:     PredictionDetails{Text: "In London"},
+	}
+
+	tests := []struct {
+		filter  *Filter
+		matches bool
+	}{
+		{&Filter{}, true},
+		{&Filter{Creator: "MTGandP"}, true},
+		{&Filter{Creator: "Michael Dickens"}, true},
+		{&Filter{Creator: "jbeshir"}, false},
+		{&Filter{Outcome: "right"}, true},
+		{&Filter{Outcome: "unknown"}, false},
+		{&Filter{CreatedAfter: time.Unix(1000, 0)}, true},
+		{&Filter{CreatedBefore: time.Unix(1000, 0)}, false},
+		{&Filter{DeadlineAfter: time.Unix(6000, 0)}, false},
+		{&Filter{DeadlineBefore: time.Unix(6000, 0)}, true},
+		{&Filter{Text: "RAIN"}, true},
+		{&Filter{Text: "london"}, true},
+		{&Filter{Text: "snow"}, false},
+	}
+	for i, test := range tests {
+		if test.filter.Matches(p) != test.matches {
+			t.Errorf("Incorrect match for filter %d (%+v); should be %t, was %t", i, test.filter, test.matches, !test.matches)
+		}
+	}
+}
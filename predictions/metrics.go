@@ -0,0 +1,67 @@
+package predictions
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/metrics"
+)
+
+// Metrics counts the items a source extracts, and warnings for those missing fields
+// which every item should have, typically meaning the site's markup has changed.
+type Metrics struct {
+	extracted *metrics.Counter
+	warnings  *metrics.Counter
+}
+
+// NewMetrics registers the source's metrics. Items are labelled by their kind, prediction
+// or response, and the page they were extracted from: list, page or api.
+func NewMetrics(r *metrics.Registry) *Metrics {
+	return &Metrics{
+		extracted: r.NewCounter("predictionbook_extracted_items_total", "Predictions and responses extracted, by kind and the page they were extracted from.", "kind", "source"),
+		warnings:  r.NewCounter("predictionbook_extraction_warnings_total", "Extracted items missing a required field, by kind, source page and field.", "kind", "source", "field"),
+	}
+}
+
+func (m *Metrics) observeSummaries(source string, predictions []*PredictionSummary) {
+	if m == nil {
+		return
+	}
+	m.extracted.Add(float64(len(predictions)), "prediction", source)
+	for _, p := range predictions {
+		for _, field := range summaryMissingFields(p) {
+			m.warnings.Inc("prediction", source, field)
+		}
+	}
+}
+
+func (m *Metrics) observeResponses(source string, responses []*PredictionResponse) {
+	if m == nil {
+		return
+	}
+	m.extracted.Add(float64(len(responses)), "response", source)
+	for _, r := range responses {
+		if r.Time.IsZero() {
+			m.warnings.Inc("response", source, "time")
+		}
+		if r.User == "" {
+			m.warnings.Inc("response", source, "user")
+		}
+	}
+}
+
+func summaryMissingFields(p *PredictionSummary) (fields []string) {
+	if p.Id == 0 {
+		fields = append(fields, "id")
+	}
+	if p.Title == "" {
+		fields = append(fields, "title")
+	}
+	if p.Creator == "" {
+		fields = append(fields, "creator")
+	}
+	if p.Created.IsZero() {
+		fields = append(fields, "created")
+	}
+	if p.Deadline.IsZero() {
+		fields = append(fields, "deadline")
+	}
+	return
+}
@@ -0,0 +1,137 @@
+package predictions
+
+import (
+	"sync"
+	"time"
+)
+
+// progressRateWindow is the number of most recent pages the current rate is measured over.
+const progressRateWindow = 20
+
+type ProgressStage int64
+
+const (
+	ListingPredictions ProgressStage = iota
+	RetrievingResponses
+)
+
+func (s ProgressStage) String() string {
+	switch s {
+	case ListingPredictions:
+		return "listing predictions"
+	case RetrievingResponses:
+		return "retrieving responses"
+	default:
+		return "unknown"
+	}
+}
+
+// Progress is a snapshot of a crawl's progress through one stage. While listing predictions,
+// pages are list pages and PagesTotal is the last page number, or zero if not yet known;
+// while retrieving responses, pages are prediction pages, one per prediction.
+type Progress struct {
+	Stage       ProgressStage
+	PagesDone   int64
+	PagesTotal  int64
+	Predictions int64
+	Responses   int64
+	// Errors counts failed requests, including those which were retried successfully.
+	Errors int64
+	// Rate is pages retrieved per second, over the most recent pages.
+	Rate    float64
+	Elapsed time.Duration
+}
+
+// Remaining estimates the time left in the stage at the current rate, or returns -1
+// if the total is unknown or nothing has been retrieved yet.
+func (p Progress) Remaining() time.Duration {
+	if p.PagesTotal == 0 || p.Rate == 0 {
+		return -1
+	}
+	if p.PagesDone >= p.PagesTotal {
+		return 0
+	}
+	return time.Duration(float64(p.PagesTotal-p.PagesDone) / p.Rate * float64(time.Second))
+}
+
+// ProgressFunc is called synchronously after each page is retrieved or request fails,
+// so it should return quickly.
+type ProgressFunc func(Progress)
+
+// progressTracker accumulates progress through a stage, reporting after each change.
+// A nil tracker, used when nobody is listening, does nothing.
+type progressTracker struct {
+	mu       sync.Mutex
+	report   ProgressFunc
+	now      func() time.Time
+	started  time.Time
+	recent   []time.Time
+	progress Progress
+}
+
+func newProgressTracker(report ProgressFunc, stage ProgressStage, total int64) *progressTracker {
+	if report == nil {
+		return nil
+	}
+
+	t := &progressTracker{
+		report: report,
+		now:    time.Now,
+	}
+	t.started = t.now()
+	t.progress.Stage = stage
+	t.progress.PagesTotal = total
+	return t
+}
+
+func (t *progressTracker) pageDone(total int64, predictions, responses int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.recent = append(t.recent, now)
+	if len(t.recent) > progressRateWindow {
+		t.recent = t.recent[1:]
+	}
+
+	t.progress.PagesDone++
+	if total > 0 {
+		t.progress.PagesTotal = total
+	}
+	t.progress.Predictions += int64(predictions)
+	t.progress.Responses += int64(responses)
+
+	// Measure from the stage start until the window fills, so the first page counts
+	windowStart := t.started
+	if len(t.recent) == progressRateWindow {
+		windowStart = t.recent[0]
+	}
+	pages := float64(len(t.recent))
+	if len(t.recent) == progressRateWindow {
+		pages--
+	}
+	if elapsed := now.Sub(windowStart).Seconds(); elapsed > 0 {
+		t.progress.Rate = pages / elapsed
+	}
+
+	t.sendLocked(now)
+}
+
+func (t *progressTracker) failed() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.progress.Errors++
+	t.sendLocked(t.now())
+}
+
+func (t *progressTracker) sendLocked(now time.Time) {
+	t.progress.Elapsed = now.Sub(t.started)
+	t.report(t.progress)
+}
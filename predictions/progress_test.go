@@ -0,0 +1,81 @@
+package predictions
+
+import (
+	"context"
+	"errors"
+	"github.com/jbeshir/predictionbook-extractor/logging"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProgressListPages(t *testing.T) {
+	t.Parallel()
+
+	var reports []Progress
+	_, err := allListPagesSince(context.Background(), time.Time{}, func(ctx context.Context, index int64) ([]*PredictionSummary, *PredictionListPageInfo, error) {
+		return []*PredictionSummary{{Id: index * 2}, {Id: index*2 + 1}}, &PredictionListPageInfo{LastPage: 3}, nil
+	}, func(p Progress) {
+		reports = append(reports, p)
+	}, logging.Discard)
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+
+	if len(reports) != 3 {
+		t.Fatalf("Incorrect number of progress reports; should be %d, was %d", 3, len(reports))
+	}
+	last := reports[2]
+	if last.Stage != ListingPredictions || last.PagesDone != 3 || last.PagesTotal != 3 || last.Predictions != 6 {
+		t.Errorf("Incorrect final progress; should be 3/3 pages and 6 predictions listing predictions, was %+v", last)
+	}
+	if last.Remaining() != 0 {
+		t.Errorf("Incorrect time remaining when finished; should be 0, was %s", last.Remaining())
+	}
+}
+
+func TestProgressResponses(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	failedOnce := make(map[int64]bool)
+	var reports []Progress
+	_, _, err := allPredictionResponses(context.Background(), []*PredictionSummary{{Id: 1}, {Id: 2}, {Id: 3}},
+		func(ctx context.Context, prediction int64) (*PredictionSummary, []*PredictionResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if prediction == 2 && !failedOnce[prediction] {
+				failedOnce[prediction] = true
+				return nil, nil, errors.New("temporary failure")
+			}
+			return &PredictionSummary{Id: prediction}, []*PredictionResponse{{Prediction: prediction}, {Prediction: prediction}}, nil
+		}, func(p Progress) {
+			reports = append(reports, p)
+		}, logging.Discard)
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+
+	last := reports[len(reports)-1]
+	if last.Stage != RetrievingResponses || last.PagesDone != 3 || last.PagesTotal != 3 || last.Responses != 6 || last.Errors != 1 {
+		t.Errorf("Incorrect final progress; should be 3/3 pages, 6 responses and 1 error retrieving responses, was %+v", last)
+	}
+}
+
+func TestProgressRemaining(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		progress  Progress
+		remaining time.Duration
+	}{
+		{Progress{PagesDone: 10, PagesTotal: 30, Rate: 2}, 10 * time.Second},
+		{Progress{PagesDone: 10, PagesTotal: 0, Rate: 2}, -1},
+		{Progress{PagesDone: 0, PagesTotal: 30, Rate: 0}, -1},
+	}
+	for _, test := range tests {
+		if test.progress.Remaining() != test.remaining {
+			t.Errorf("Incorrect time remaining for %+v; should be %s, was %s", test.progress, test.remaining, test.progress.Remaining())
+		}
+	}
+}
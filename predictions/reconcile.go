@@ -0,0 +1,166 @@
+package predictions
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SummarySource int64
+
+const (
+	ListPage SummarySource = iota
+	PredictionPage
+)
+
+func (s SummarySource) String() string {
+	switch s {
+	case ListPage:
+		return "list"
+	case PredictionPage:
+		return "page"
+	default:
+		return "unknown"
+	}
+}
+
+// ReconciledSummary is the canonical summary for a prediction, built from its list page entry
+// and its prediction page. Sources records which of the two each field was taken from.
+type ReconciledSummary struct {
+	PredictionSummary
+	Sources  map[string]SummarySource
+	Findings []*DataQualityFinding
+}
+
+// DataQualityFinding records a field on which the list page and prediction page disagree.
+type DataQualityFinding struct {
+	Prediction int64
+	Field      string
+	ListValue  string
+	PageValue  string
+}
+
+// The list page rounds mean confidence to a whole percentage.
+const meanConfidenceTolerance = 0.005
+
+// ReconcileSummaries merges summaries extracted from list pages with those extracted from
+// prediction pages. Prediction page values are preferred, as they are computed from the
+// individual responses, falling back to the list page where the page value is missing.
+// Predictions present in only one of the two are passed through unchanged.
+func ReconcileSummaries(listSummaries, pageSummaries []*PredictionSummary) (reconciled []*ReconciledSummary) {
+	pageById := make(map[int64]*PredictionSummary)
+	for _, p := range pageSummaries {
+		pageById[p.Id] = p
+	}
+
+	listIds := make(map[int64]bool)
+	for _, l := range listSummaries {
+		listIds[l.Id] = true
+		reconciled = append(reconciled, reconcileSummary(l, pageById[l.Id]))
+	}
+	for _, p := range pageSummaries {
+		if !listIds[p.Id] {
+			reconciled = append(reconciled, reconcileSummary(nil, p))
+		}
+	}
+
+	sort.Slice(reconciled, func(i, j int) bool {
+		return reconciled[i].Id < reconciled[j].Id
+	})
+	return
+}
+
+func reconcileSummary(list, page *PredictionSummary) (r *ReconciledSummary) {
+	r = new(ReconciledSummary)
+	r.Sources = make(map[string]SummarySource)
+
+	if page == nil || list == nil {
+		source := ListPage
+		summary := list
+		if list == nil {
+			source = PredictionPage
+			summary = page
+		}
+
+		r.PredictionSummary = *summary
+		for _, field := range []string{"Title", "Creator", "CreatorSlug", "Created", "Deadline", "Judged", "MeanConfidence", "WagerCount", "Outcome", "Details", "Judgements"} {
+			r.Sources[field] = source
+		}
+		return
+	}
+
+	r.Id = list.Id
+	r.Details = page.Details
+	r.Sources["Details"] = PredictionPage
+	r.Judgements = page.Judgements
+	r.Sources["Judgements"] = PredictionPage
+
+	r.Title = reconcileString(r, "Title", list.Title, strings.TrimSpace(page.Title))
+	r.Creator = reconcileString(r, "Creator", list.Creator, page.Creator)
+	r.CreatorSlug = reconcileString(r, "CreatorSlug", list.CreatorSlug, page.CreatorSlug)
+	r.Created = reconcileTime(r, "Created", list.Created, page.Created)
+	r.Deadline = reconcileTime(r, "Deadline", list.Deadline, page.Deadline)
+	r.Judged = reconcileTime(r, "Judged", list.Judged, page.Judged)
+
+	r.MeanConfidence = page.MeanConfidence
+	r.Sources["MeanConfidence"] = PredictionPage
+	if math.IsNaN(page.MeanConfidence) && !math.IsNaN(list.MeanConfidence) {
+		r.MeanConfidence = list.MeanConfidence
+		r.Sources["MeanConfidence"] = ListPage
+	} else if math.Abs(list.MeanConfidence-page.MeanConfidence) > meanConfidenceTolerance {
+		r.addFinding("MeanConfidence",
+			strconv.FormatFloat(list.MeanConfidence, 'f', -1, 64),
+			strconv.FormatFloat(page.MeanConfidence, 'f', -1, 64))
+	}
+
+	r.WagerCount = page.WagerCount
+	r.Sources["WagerCount"] = PredictionPage
+	if list.WagerCount != page.WagerCount {
+		r.addFinding("WagerCount", strconv.FormatInt(list.WagerCount, 10), strconv.FormatInt(page.WagerCount, 10))
+	}
+
+	r.Outcome = page.Outcome
+	r.Sources["Outcome"] = PredictionPage
+	if list.Outcome != page.Outcome {
+		r.addFinding("Outcome", list.Outcome.String(), page.Outcome.String())
+	}
+
+	return
+}
+
+func reconcileString(r *ReconciledSummary, field string, list, page string) string {
+	if page == "" {
+		r.Sources[field] = ListPage
+		return list
+	}
+
+	if list != "" && list != page {
+		r.addFinding(field, list, page)
+	}
+	r.Sources[field] = PredictionPage
+	return page
+}
+
+func reconcileTime(r *ReconciledSummary, field string, list, page time.Time) time.Time {
+	if page.IsZero() {
+		r.Sources[field] = ListPage
+		return list
+	}
+
+	if !list.IsZero() && !list.Equal(page) {
+		r.addFinding(field, strconv.FormatInt(list.Unix(), 10), strconv.FormatInt(page.Unix(), 10))
+	}
+	r.Sources[field] = PredictionPage
+	return page
+}
+
+func (r *ReconciledSummary) addFinding(field, listValue, pageValue string) {
+	r.Findings = append(r.Findings, &DataQualityFinding{
+		Prediction: r.Id,
+		Field:      field,
+		ListValue:  listValue,
+		PageValue:  pageValue,
+	})
+}
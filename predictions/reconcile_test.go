@@ -0,0 +1,83 @@
+package predictions
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReconcileSummaries(t *testing.T) {
+	t.Parallel()
+
+	listSummaries := []*PredictionSummary{
+		{
+			Id:             193436,
+			Title:          "Convincing evidence will prove that Amazon, Apple, etc.'s chips were compromised.",
+			Creator:        "krazemon",
+			Created:        time.Unix(1538836809, 0),
+			Deadline:       time.Unix(1554552000, 0),
+			MeanConfidence: 0.26,
+			WagerCount:     8,
+			Outcome:        Unknown,
+		},
+		{
+			Id:    5,
+			Title: "List only",
+		},
+	}
+
+	pageSummary := ExtractPredictionSummaryResponsePage(193436, testHtmlLoad(t, "test_responses.html"))
+	reconciled := ReconcileSummaries(listSummaries, []*PredictionSummary{pageSummary})
+
+	if len(reconciled) != 2 {
+		t.Fatalf("Incorrect number of reconciled summaries, should be %d, was %d", 2, len(reconciled))
+	}
+	if reconciled[0].Id != 5 || reconciled[0].Sources["Title"] != ListPage {
+		t.Errorf("List only summary should have been passed through from the list page, was %+v", reconciled[0])
+	}
+
+	r := reconciled[1]
+	if r.WagerCount != 7 || r.Sources["WagerCount"] != PredictionPage {
+		t.Errorf("Incorrect reconciled wager count, should be %d from %s, was %d from %s", 7, PredictionPage, r.WagerCount, r.Sources["WagerCount"])
+	}
+	if math.Abs(r.MeanConfidence-0.25857142857) > 0.00001 {
+		t.Errorf("Incorrect reconciled mean confidence, should be %g, was %g", 0.25857142857, r.MeanConfidence)
+	}
+	if r.Outcome != Right {
+		t.Errorf("Incorrect reconciled outcome, should be %d, was %d", Right, r.Outcome)
+	}
+	if r.Details.Text == "" {
+		t.Errorf("Reconciled summary should have details from the prediction page")
+	}
+
+	if len(r.Findings) != 2 {
+		t.Fatalf("Incorrect number of findings, should be %d, was %d: %+v", 2, len(r.Findings), r.Findings)
+	}
+	if r.Findings[0].Field != "WagerCount" || r.Findings[0].ListValue != "8" || r.Findings[0].PageValue != "7" {
+		t.Errorf("Incorrect first finding, should be a wager count disagreement of 8 against 7, was %+v", r.Findings[0])
+	}
+	if r.Findings[1].Field != "Outcome" || r.Findings[1].ListValue != "unknown" || r.Findings[1].PageValue != "right" {
+		t.Errorf("Incorrect second finding, should be an outcome disagreement of unknown against right, was %+v", r.Findings[1])
+	}
+}
+
+func TestReconcileUnknownMeanConfidence(t *testing.T) {
+	t.Parallel()
+
+	list := []*PredictionSummary{
+		{Id: 1, MeanConfidence: math.NaN()},
+		{Id: 2, MeanConfidence: 0.4},
+	}
+	pages := []*PredictionSummary{
+		{Id: 1, MeanConfidence: math.NaN()},
+		{Id: 2, MeanConfidence: math.NaN()},
+	}
+	reconciled := ReconcileSummaries(list, pages)
+
+	if !math.IsNaN(reconciled[0].MeanConfidence) {
+		t.Errorf("Incorrect mean confidence when neither page shows one; should be NaN, was %g", reconciled[0].MeanConfidence)
+	}
+	if reconciled[1].MeanConfidence != 0.4 || reconciled[1].Sources["MeanConfidence"] != ListPage {
+		t.Errorf("Incorrect mean confidence; should be %g from %s, was %g from %s", 0.4, ListPage, reconciled[1].MeanConfidence, reconciled[1].Sources["MeanConfidence"])
+	}
+}
@@ -4,8 +4,9 @@ import (
 	"context"
 	"errors"
 	"github.com/PuerkitoBio/goquery"
-	"github.com/golang/glog"
+	"github.com/jbeshir/predictionbook-extractor/logging"
 	"golang.org/x/net/html"
+	"net/url"
 	"sort"
 	"strconv"
 	"time"
@@ -14,19 +15,48 @@ import (
 type Source struct {
 	baseUrl     string
 	htmlFetcher HtmlFetcher
+	progress    ProgressFunc
+	metrics     *Metrics
+	logger      logging.Logger
 }
 
 type HtmlFetcher interface {
 	GetHtml(ctx context.Context, url string) (*html.Node, error)
 }
 
-func NewSource(htmlFetcher HtmlFetcher, baseUrl string) *Source {
+// PredictionSource is implemented by both Source, which scrapes the site's HTML pages,
+// and ApiSource, which uses the JSON API.
+type PredictionSource interface {
+	Latest(ctx context.Context) (*PredictionSummary, error)
+	AllPredictions(ctx context.Context) (predictions []*PredictionSummary, err error)
+	AllPredictionsSince(ctx context.Context, t time.Time) (predictions []*PredictionSummary, err error)
+	AllPredictionResponses(ctx context.Context, predictions []*PredictionSummary) (summaries []*PredictionSummary, responses []*PredictionResponse, err error)
+	RetrievePredictionResponses(ctx context.Context, prediction int64) (summary *PredictionSummary, responses []*PredictionResponse, err error)
+	SetProgressFunc(f ProgressFunc)
+	SetMetrics(m *Metrics)
+}
+
+// NewSource returns a source scraping the PredictionBook instance at baseUrl. Progress
+// through multi-page retrievals is logged at debug level to the logger, which may be nil.
+func NewSource(htmlFetcher HtmlFetcher, baseUrl string, logger logging.Logger) *Source {
 	return &Source{
 		baseUrl:     baseUrl,
 		htmlFetcher: htmlFetcher,
+		logger:      logging.OrDiscard(logger),
 	}
 }
 
+// SetProgressFunc sets a function to be called with progress through retrieving all
+// predictions or all their responses, or nil to stop reporting progress.
+func (s *Source) SetProgressFunc(f ProgressFunc) {
+	s.progress = f
+}
+
+// SetMetrics counts the predictions and responses extracted with the given metrics, or stops if nil.
+func (s *Source) SetMetrics(m *Metrics) {
+	s.metrics = m
+}
+
 func (s *Source) Latest(ctx context.Context) (*PredictionSummary, error) {
 	latest, _, err := s.RetrievePredictionListPage(ctx, 1)
 	if err != nil {
@@ -55,13 +85,117 @@ func (s *Source) AllPredictions(ctx context.Context) (predictions []*PredictionS
 
 func (s *Source) AllPredictionsSince(ctx context.Context, t time.Time) (predictions []*PredictionSummary, err error) {
 
+	return allListPagesSince(ctx, t, s.RetrievePredictionListPage, s.progress, s.logger)
+}
+
+func (s *Source) AllUserPredictions(ctx context.Context, slug string) (predictions []*PredictionSummary, err error) {
+
+	return allListPagesSince(ctx, time.Time{}, func(ctx context.Context, index int64) ([]*PredictionSummary, *PredictionListPageInfo, error) {
+		return s.RetrieveUserPredictionListPage(ctx, slug, index)
+	}, s.progress, s.logger)
+}
+
+func (s *Source) AllPredictionResponses(ctx context.Context, predictions []*PredictionSummary) (summaries []*PredictionSummary, responses []*PredictionResponse, err error) {
+	return allPredictionResponses(ctx, predictions, s.RetrievePredictionResponses, s.progress, s.logger)
+}
+
+func (s *Source) RetrievePredictionResponses(ctx context.Context, prediction int64) (summary *PredictionSummary, responses []*PredictionResponse, err error) {
+	rootNode, err := s.htmlFetcher.GetHtml(ctx, s.baseUrl+"/predictions/"+strconv.FormatInt(prediction, 10))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page := goquery.NewDocumentFromNode(rootNode)
+	page.Find(".response").Each(func(i int, responseSelector *goquery.Selection) {
+		response := ExtractPredictionResponse(responseSelector.Nodes[0], prediction)
+		responses = append(responses, response)
+	})
+
+	summary = ExtractPredictionSummaryResponsePage(prediction, rootNode)
+	s.metrics.observeSummaries("page", []*PredictionSummary{summary})
+	s.metrics.observeResponses("page", responses)
+	return summary, responses, nil
+}
+
+func (s *Source) RetrievePredictionListPage(ctx context.Context, index int64) (predictions []*PredictionSummary, pageInfo *PredictionListPageInfo, err error) {
+	rootNode, err := s.htmlFetcher.GetHtml(ctx, s.baseUrl+"/predictions/page/"+strconv.FormatInt(index, 10))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	predictions = extractPredictionList(rootNode)
+	s.metrics.observeSummaries("list", predictions)
+	return predictions, ExtractPredictionListPageInfo(rootNode, index), nil
+}
+
+func (s *Source) RetrieveUserProfile(ctx context.Context, slug string) (profile *UserProfile, err error) {
+	rootNode, err := s.htmlFetcher.GetHtml(ctx, s.baseUrl+"/users/"+url.PathEscape(slug))
+	if err != nil {
+		return nil, err
+	}
+
+	return ExtractUserProfile(slug, rootNode), nil
+}
+
+func (s *Source) RetrieveUserPredictionListPage(ctx context.Context, slug string, index int64) (predictions []*PredictionSummary, pageInfo *PredictionListPageInfo, err error) {
+	rootNode, err := s.htmlFetcher.GetHtml(ctx, s.baseUrl+"/users/"+url.PathEscape(slug)+"?page="+strconv.FormatInt(index, 10))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	predictions = extractPredictionList(rootNode)
+	s.metrics.observeSummaries("list", predictions)
+	return predictions, ExtractPredictionListPageInfo(rootNode, index), nil
+}
+
+func (s *Source) AllUpcomingPredictions(ctx context.Context) (predictions []*PredictionSummary, err error) {
+
+	predictions, err = allListPagesSince(ctx, time.Time{}, s.RetrieveUpcomingPage, s.progress, s.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(predictions, func(i, j int) bool {
+		return predictions[i].Deadline.Before(predictions[j].Deadline)
+	})
+
+	return predictions, nil
+}
+
+func (s *Source) RetrieveUpcomingPage(ctx context.Context, index int64) (predictions []*PredictionSummary, pageInfo *PredictionListPageInfo, err error) {
+	rootNode, err := s.htmlFetcher.GetHtml(ctx, s.baseUrl+"/predictions/future?page="+strconv.FormatInt(index, 10))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	predictions = extractPredictionList(rootNode)
+	s.metrics.observeSummaries("list", predictions)
+	return predictions, ExtractPredictionListPageInfo(rootNode, index), nil
+}
+
+func (s *Source) RetrieveHappenstance(ctx context.Context) (predictions []*PredictionSummary, events []*ActivityEvent, err error) {
+	rootNode, err := s.htmlFetcher.GetHtml(ctx, s.baseUrl+"/happenstance")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	predictions, events = ExtractHappenstance(rootNode)
+	return predictions, events, nil
+}
+
+func allListPagesSince(ctx context.Context, t time.Time, retrievePage func(ctx context.Context, index int64) ([]*PredictionSummary, *PredictionListPageInfo, error), report ProgressFunc, logger logging.Logger) (predictions []*PredictionSummary, err error) {
+
+	progress := newProgressTracker(report, ListingPredictions, 0)
 	currentPage := int64(1)
 	totalPages := int64(1)
 	for {
-		newPredictions, pageInfo, err := s.RetrievePredictionListPage(ctx, currentPage)
+		newPredictions, pageInfo, err := retrievePage(ctx, currentPage)
 		if err != nil {
+			progress.failed()
+			logger.Debug("Failed to retrieve list page", logging.PageKey, currentPage, logging.ErrorKey, err)
 			return nil, err
 		}
+		logger.Debug("Retrieved list page", logging.PageKey, currentPage, "last_page", pageInfo.LastPage, "predictions", len(newPredictions))
 
 		lastIncluded := len(newPredictions) - 1
 		for lastIncluded > -1 && newPredictions[lastIncluded].Created.Before(t) {
@@ -69,6 +203,7 @@ func (s *Source) AllPredictionsSince(ctx context.Context, t time.Time) (predicti
 		}
 
 		predictions = append(predictions, newPredictions[:lastIncluded+1]...)
+		progress.pageDone(pageInfo.LastPage, lastIncluded+1, 0)
 
 		if lastIncluded < len(newPredictions)-1 {
 			break
@@ -83,6 +218,23 @@ func (s *Source) AllPredictionsSince(ctx context.Context, t time.Time) (predicti
 	}
 
 	// Sort and remove duplicates; they can appear due to predictions made during retrieval
+	predictions = sortPredictionSummaries(predictions)
+
+	return
+}
+
+func extractPredictionList(rootNode *html.Node) (predictions []*PredictionSummary) {
+	page := goquery.NewDocumentFromNode(rootNode)
+
+	page.Find(".prediction").Each(func(i int, predictionSelector *goquery.Selection) {
+		prediction := ExtractPredictionSummary(predictionSelector.Nodes[0])
+		predictions = append(predictions, prediction)
+	})
+
+	return
+}
+
+func sortPredictionSummaries(predictions []*PredictionSummary) []*PredictionSummary {
 	sort.Slice(predictions, func(i, j int) bool {
 		return predictions[i].Id < predictions[j].Id
 	})
@@ -93,25 +245,35 @@ func (s *Source) AllPredictionsSince(ctx context.Context, t time.Time) (predicti
 		}
 	}
 
-	return
+	return predictions
 }
 
-func (s *Source) AllPredictionResponses(ctx context.Context, predictions []*PredictionSummary) (summaries []*PredictionSummary, responses []*PredictionResponse, err error) {
-	respCh := make(chan struct{s *PredictionSummary; rs []*PredictionResponse}, 1)
+func allPredictionResponses(ctx context.Context, predictions []*PredictionSummary, retrieve func(ctx context.Context, prediction int64) (*PredictionSummary, []*PredictionResponse, error), report ProgressFunc, logger logging.Logger) (summaries []*PredictionSummary, responses []*PredictionResponse, err error) {
+	started := time.Now()
+	progress := newProgressTracker(report, RetrievingResponses, int64(len(predictions)))
+	respCh := make(chan struct {
+		s  *PredictionSummary
+		rs []*PredictionResponse
+	}, 1)
 	errCh := make(chan error)
 
 	launched := 0
 	for _, p := range predictions {
 		go func(prediction int64) {
 			var err error
-			for attempt := 0; attempt < 3; attempt++ {
+			for attempt := 1; attempt <= 3; attempt++ {
 				var rs []*PredictionResponse
 				var sum *PredictionSummary
-				sum, rs, err = s.RetrievePredictionResponses(ctx, prediction)
+				sum, rs, err = retrieve(ctx, prediction)
 				if err == nil {
-					respCh <- struct{s *PredictionSummary; rs []*PredictionResponse}{s:sum,rs:rs}
+					respCh <- struct {
+						s  *PredictionSummary
+						rs []*PredictionResponse
+					}{s: sum, rs: rs}
 					break
 				}
+				progress.failed()
+				logger.Debug("Failed to retrieve prediction", logging.PredictionIdKey, prediction, logging.AttemptKey, attempt, logging.ErrorKey, err)
 			}
 			if err != nil {
 				errCh <- err
@@ -124,22 +286,17 @@ func (s *Source) AllPredictionResponses(ctx context.Context, predictions []*Pred
 	for i := 0; i < launched; i++ {
 		select {
 		case err := <-errCh:
-			if glog.V(2) {
-				glog.Infof("Got an error while generating responses: %s\n", err)
-			}
+			logger.Warn("Giving up retrieving responses", logging.DurationKey, time.Since(started), logging.ErrorKey, err)
 			return nil, nil, err
 		case r := <-respCh:
 			summaries = append(summaries, r.s)
 			responses = append(responses, r.rs...)
-			if glog.V(2) {
-				glog.Infof("Added %d responses, now collected %d responses...\n", len(r.rs), len(responses))
-			}
+			progress.pageDone(0, 1, len(r.rs))
+			logger.Debug("Collected responses", logging.PredictionIdKey, r.s.Id, "responses", len(r.rs), "total_responses", len(responses))
 		}
 	}
 
-	if glog.V(2) {
-		glog.Infof("Finished collecting responses\n")
-	}
+	logger.Debug("Finished collecting responses", "responses", len(responses), logging.DurationKey, time.Since(started))
 
 	sort.Slice(summaries, func(i, j int) bool {
 		return summaries[i].Id < summaries[j].Id
@@ -153,35 +310,3 @@ func (s *Source) AllPredictionResponses(ctx context.Context, predictions []*Pred
 
 	return summaries, responses, nil
 }
-
-func (s *Source) RetrievePredictionResponses(ctx context.Context, prediction int64) (summary *PredictionSummary, responses []*PredictionResponse, err error) {
-	rootNode, err := s.htmlFetcher.GetHtml(ctx, s.baseUrl+"/predictions/"+strconv.FormatInt(prediction, 10))
-	if err != nil {
-		return nil, nil, err
-	}
-
-	page := goquery.NewDocumentFromNode(rootNode)
-	page.Find(".response").Each(func(i int, responseSelector *goquery.Selection) {
-		response := ExtractPredictionResponse(responseSelector.Nodes[0], prediction)
-		responses = append(responses, response)
-	})
-
-	return ExtractPredictionSummaryResponsePage(prediction, rootNode), responses, nil
-}
-
-func (s *Source) RetrievePredictionListPage(ctx context.Context, index int64) (predictions []*PredictionSummary, pageInfo *PredictionListPageInfo, err error) {
-	rootNode, err := s.htmlFetcher.GetHtml(ctx, s.baseUrl+"/predictions/page/"+strconv.FormatInt(index, 10))
-	if err != nil {
-		return nil, nil, err
-	}
-	page := goquery.NewDocumentFromNode(rootNode)
-
-	page.Find(".prediction").Each(func(i int, predictionSelector *goquery.Selection) {
-		prediction := ExtractPredictionSummary(predictionSelector.Nodes[0])
-		predictions = append(predictions, prediction)
-	})
-
-	pageInfo = ExtractPredictionListPageInfo(rootNode, index)
-
-	return predictions, pageInfo, nil
-}
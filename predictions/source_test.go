@@ -2,6 +2,7 @@ package predictions
 
 import (
 	"context"
+	"errors"
 	"golang.org/x/net/html"
 	"strconv"
 	"sync"
@@ -32,7 +33,7 @@ func TestRetrievePredictionListPage(t *testing.T) {
 		},
 	}
 
-	s := NewSource(fetcher, "https://example.org")
+	s := NewSource(fetcher, "https://example.org", nil)
 	predictions, pageInfo, err := s.RetrievePredictionListPage(ctx, 2)
 	if err != nil {
 		t.Errorf("Error should have been nil, was %s", err)
@@ -63,7 +64,7 @@ func TestRetrievePredictionResponses(t *testing.T) {
 		},
 	}
 
-	s := NewSource(fetcher, "https://example.org")
+	s := NewSource(fetcher, "https://example.org", nil)
 	summary, responses, err := s.RetrievePredictionResponses(ctx, 193436)
 	if err != nil {
 		t.Errorf("Error should have been nil, was %s", err)
@@ -98,7 +99,7 @@ func TestLatest(t *testing.T) {
 		},
 	}
 
-	s := NewSource(fetcher, "https://example.org")
+	s := NewSource(fetcher, "https://example.org", nil)
 	summary, err := s.Latest(ctx)
 	if err != nil {
 		t.Errorf("Error should have been nil, was %s", err)
@@ -123,7 +124,7 @@ func TestPredictionPageCount(t *testing.T) {
 		},
 	}
 
-	s := NewSource(fetcher, "https://example.org")
+	s := NewSource(fetcher, "https://example.org", nil)
 	pageCount, err := s.PredictionPageCount(ctx)
 	if err != nil {
 		t.Errorf("Error should have been nil, was %s", err)
@@ -155,7 +156,7 @@ func TestAllPredictions(t *testing.T) {
 		},
 	}
 
-	s := NewSource(fetcher, "https://example.org")
+	s := NewSource(fetcher, "https://example.org", nil)
 	summaries, err := s.AllPredictions(ctx)
 	if err != nil {
 		t.Errorf("Error should have been nil, was %s", err)
@@ -195,7 +196,7 @@ func TestAllPredictionsSince(t *testing.T) {
 		},
 	}
 
-	s := NewSource(fetcher, "https://example.org")
+	s := NewSource(fetcher, "https://example.org", nil)
 	summaries, err := s.AllPredictionsSince(ctx, time.Unix(1537670940, 0))
 	if err != nil {
 		t.Errorf("Error should have been nil, was %s", err)
@@ -253,7 +254,7 @@ func TestAllPredictionResponses(t *testing.T) {
 		},
 	}
 
-	s := NewSource(fetcher, "https://example.org")
+	s := NewSource(fetcher, "https://example.org", nil)
 	newSummaries, responses, err := s.AllPredictionResponses(ctx, summaries)
 	if err != nil {
 		t.Errorf("Error should have been nil, was %s", err)
@@ -278,3 +279,212 @@ func TestAllPredictionResponses(t *testing.T) {
 		t.Errorf("2nd summary had wrong ID; should be %d, was %d", 400, newSummaries[1].Id)
 	}
 }
+
+func TestRetrieveUserProfile(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	fetcher := &TestHtmlFetcher{
+		GetHtmlFunc: func(ctx context.Context, url string) (*html.Node, error) {
+			if url != "https://example.org/users/jbeshir" {
+				t.Errorf("Incorrect user page requested, should be %s, was %s", "https://example.org/users/jbeshir", url)
+			}
+
+			return testHtmlLoad(t, "test_user.html"), nil
+		},
+	}
+
+	s := NewSource(fetcher, "https://example.org", nil)
+	profile, err := s.RetrieveUserProfile(ctx, "jbeshir")
+	if err != nil {
+		t.Errorf("Error should have been nil, was %s", err)
+	}
+	if profile.DisplayName != "jbeshir" {
+		t.Errorf("Incorrect user display name; should be %s, was %s", "jbeshir", profile.DisplayName)
+	}
+}
+
+func TestAllUserPredictions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	callCount := 0
+	fetcher := &TestHtmlFetcher{
+		GetHtmlFunc: func(ctx context.Context, url string) (*html.Node, error) {
+			callCount++
+			expectedUrl := "https://example.org/users/jbeshir?page=" + strconv.Itoa(callCount)
+			if url != expectedUrl {
+				t.Errorf("Incorrect user page requested, should be %s, was %s", expectedUrl, url)
+			}
+
+			return testHtmlLoad(t, "test_user.html"), nil
+		},
+	}
+
+	s := NewSource(fetcher, "https://example.org", nil)
+	summaries, err := s.AllUserPredictions(ctx, "jbeshir")
+	if err != nil {
+		t.Errorf("Error should have been nil, was %s", err)
+	}
+	if callCount != 3 {
+		t.Errorf("GetHtml called incorrect number of times, should be %d, was %d", 3, callCount)
+	}
+	if len(summaries) != 2 {
+		t.Fatalf("Retrieved incorrect number of predictions; should be %d, was %d", 2, len(summaries))
+	}
+	if summaries[0].Id != 193401 || summaries[0].Creator != "jbeshir" {
+		t.Errorf("First prediction was incorrect; should be %d by %s, was %d by %s", 193401, "jbeshir", summaries[0].Id, summaries[0].Creator)
+	}
+}
+
+func TestAllUpcomingPredictions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	callCount := 0
+	fetcher := &TestHtmlFetcher{
+		GetHtmlFunc: func(ctx context.Context, url string) (*html.Node, error) {
+			callCount++
+			expectedUrl := "https://example.org/predictions/future?page=" + strconv.Itoa(callCount)
+			if url != expectedUrl {
+				t.Errorf("Incorrect upcoming page requested, should be %s, was %s", expectedUrl, url)
+			}
+
+			return testHtmlLoad(t, "test_upcoming.html"), nil
+		},
+	}
+
+	s := NewSource(fetcher, "https://example.org", nil)
+	summaries, err := s.AllUpcomingPredictions(ctx)
+	if err != nil {
+		t.Errorf("Error should have been nil, was %s", err)
+	}
+	if callCount != 2 {
+		t.Errorf("GetHtml called incorrect number of times, should be %d, was %d", 2, callCount)
+	}
+	if len(summaries) != 3 {
+		t.Fatalf("Retrieved incorrect number of predictions; should be %d, was %d", 3, len(summaries))
+	}
+	if summaries[0].Id != 193350 || summaries[2].Id != 193473 {
+		t.Errorf("Predictions should be in deadline order; got %d first and %d last", summaries[0].Id, summaries[2].Id)
+	}
+}
+
+func TestRetrieveHappenstance(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	fetcher := &TestHtmlFetcher{
+		GetHtmlFunc: func(ctx context.Context, url string) (*html.Node, error) {
+			if url != "https://example.org/happenstance" {
+				t.Errorf("Incorrect happenstance page requested, should be %s, was %s", "https://example.org/happenstance", url)
+			}
+
+			return testHtmlLoad(t, "test_happenstance.html"), nil
+		},
+	}
+
+	s := NewSource(fetcher, "https://example.org", nil)
+	predictions, events, err := s.RetrieveHappenstance(ctx)
+	if err != nil {
+		t.Errorf("Error should have been nil, was %s", err)
+	}
+	if len(predictions) != 2 {
+		t.Errorf("Retrieved incorrect number of predictions; should be %d, was %d", 2, len(predictions))
+	}
+	if len(events) != 6 {
+		t.Errorf("Retrieved incorrect number of events; should be %d, was %d", 6, len(events))
+	}
+}
+
+type testLogEntry struct {
+	level string
+	msg   string
+	args  []interface{}
+}
+
+type testLogger struct {
+	mu      sync.Mutex
+	entries []testLogEntry
+}
+
+func (l *testLogger) log(level, msg string, args []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, testLogEntry{level, msg, args})
+}
+
+func (l *testLogger) Debug(msg string, args ...interface{}) { l.log("debug", msg, args) }
+func (l *testLogger) Info(msg string, args ...interface{})  { l.log("info", msg, args) }
+func (l *testLogger) Warn(msg string, args ...interface{})  { l.log("warn", msg, args) }
+func (l *testLogger) Error(msg string, args ...interface{}) { l.log("error", msg, args) }
+
+func TestAllPredictionResponsesLogsAttempts(t *testing.T) {
+	t.Parallel()
+
+	logger := new(testLogger)
+	fetcher := &TestHtmlFetcher{
+		GetHtmlFunc: func(ctx context.Context, url string) (*html.Node, error) {
+			return nil, errors.New("unavailable")
+		},
+	}
+
+	s := NewSource(fetcher, "https://example.org", logger)
+	_, _, err := s.AllPredictionResponses(context.Background(), []*PredictionSummary{{Id: 7}})
+	if err == nil {
+		t.Fatalf("Error should have been returned after every attempt failed")
+	}
+
+	var attempts []interface{}
+	for _, entry := range logger.entries {
+		if entry.msg != "Failed to retrieve prediction" {
+			continue
+		}
+		fields := make(map[interface{}]interface{})
+		for i := 0; i+1 < len(entry.args); i += 2 {
+			fields[entry.args[i]] = entry.args[i+1]
+		}
+		if fields["prediction_id"] != int64(7) {
+			t.Errorf("Incorrect prediction_id logged; should be %d, was %v", 7, fields["prediction_id"])
+		}
+		attempts = append(attempts, fields["attempt"])
+	}
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[2] != 3 {
+		t.Errorf("Incorrect attempts logged; should be [1 2 3], was %v", attempts)
+	}
+}
+
+func TestRetrieveUserPagesEscapeSlug(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var urls []string
+	fetcher := &TestHtmlFetcher{
+		GetHtmlFunc: func(ctx context.Context, url string) (*html.Node, error) {
+			urls = append(urls, url)
+			return testHtmlLoad(t, "test_user.html"), nil
+		},
+	}
+
+	s := NewSource(fetcher, "https://example.org", nil)
+	_, err := s.RetrieveUserProfile(ctx, "a/b?c")
+	if err != nil {
+		t.Errorf("Error should have been nil, was %s", err)
+	}
+	_, _, err = s.RetrieveUserPredictionListPage(ctx, "a/b?c", 2)
+	if err != nil {
+		t.Errorf("Error should have been nil, was %s", err)
+	}
+
+	expected := []string{"https://example.org/users/a%2Fb%3Fc", "https://example.org/users/a%2Fb%3Fc?page=2"}
+	for i := range expected {
+		if urls[i] != expected[i] {
+			t.Errorf("Incorrect user page requested, should be %s, was %s", expected[i], urls[i])
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package scoring
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"math"
+	"sort"
+)
+
+type UserSummary struct {
+	Slug         string
+	Name         string
+	Predictions  int64
+	Wagers       int64
+	JudgedWagers int64
+	BrierScore   float64
+	Calibration  []*CalibrationBucket
+}
+
+// CalibrationBucket groups judged wagers by confidence in 10% steps from 50% to 100%, as on
+// PredictionBook's statistics tables. Wagers below 50% are counted as the opposite wager.
+type CalibrationBucket struct {
+	Confidence float64
+	Count      int64
+	Right      int64
+	Accuracy   float64
+}
+
+// BrierScore returns the squared error of a wager against a judged outcome,
+// or NaN if the prediction is not yet judged.
+func BrierScore(confidence float64, outcome predictions.Outcome) float64 {
+	switch outcome {
+	case predictions.Right:
+		return (1 - confidence) * (1 - confidence)
+	case predictions.Wrong:
+		return confidence * confidence
+	default:
+		return math.NaN()
+	}
+}
+
+// UserSummaries summarises each user who created a prediction or wagered, sorted by slug.
+// BrierScore is the mean over the user's judged wagers, or NaN if they have none.
+func UserSummaries(ps []*predictions.PredictionSummary, responses []*predictions.PredictionResponse) (summaries []*UserSummary) {
+	bySlug := make(map[string]*UserSummary)
+	summaryFor := func(slug, name string) *UserSummary {
+		s, exists := bySlug[slug]
+		if !exists {
+			s = &UserSummary{Slug: slug, Name: name}
+			bySlug[slug] = s
+		}
+		return s
+	}
+
+	outcomes := make(map[int64]predictions.Outcome)
+	for _, p := range ps {
+		outcomes[p.Id] = p.Outcome
+		if p.CreatorSlug != "" {
+			summaryFor(p.CreatorSlug, p.Creator).Predictions++
+		}
+	}
+
+	wagersBySlug := make(map[string][]*predictions.PredictionResponse)
+	for _, r := range responses {
+		if r.UserSlug == "" || !r.Kind.IsWager() {
+			continue
+		}
+		summaryFor(r.UserSlug, r.User).Wagers++
+		wagersBySlug[r.UserSlug] = append(wagersBySlug[r.UserSlug], r)
+	}
+
+	for slug, s := range bySlug {
+		totalScore := 0.0
+		for _, r := range wagersBySlug[slug] {
+			score := BrierScore(r.Confidence, outcomes[r.Prediction])
+			if !math.IsNaN(score) {
+				totalScore += score
+				s.JudgedWagers++
+			}
+		}
+		s.BrierScore = totalScore / float64(s.JudgedWagers)
+		s.Calibration = Calibration(wagersBySlug[slug], outcomes)
+
+		summaries = append(summaries, s)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Slug < summaries[j].Slug
+	})
+	return
+}
+
+// Calibration buckets the judged wagers, given the outcome of each prediction.
+// Buckets with no wagers have a NaN accuracy.
+func Calibration(wagers []*predictions.PredictionResponse, outcomes map[int64]predictions.Outcome) (buckets []*CalibrationBucket) {
+	for i := 5; i <= 10; i++ {
+		buckets = append(buckets, &CalibrationBucket{Confidence: float64(i) / 10})
+	}
+
+	for _, r := range wagers {
+		outcome := outcomes[r.Prediction]
+		if outcome == predictions.Unknown || !r.Kind.IsWager() {
+			continue
+		}
+
+		confidence, right := r.Confidence, outcome == predictions.Right
+		if confidence < 0.5 {
+			confidence, right = 1-confidence, !right
+		}
+
+		// Round to avoid floating point error putting e.g. 0.7 in the 60% bucket
+		index := int(math.Floor(math.Round(confidence*1000)/100)) - 5
+		buckets[index].Count++
+		if right {
+			buckets[index].Right++
+		}
+	}
+
+	for _, b := range buckets {
+		b.Accuracy = float64(b.Right) / float64(b.Count)
+		if b.Count == 0 {
+			b.Accuracy = math.NaN()
+		}
+	}
+
+	return
+}
@@ -0,0 +1,63 @@
+package scoring
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"math"
+	"testing"
+)
+
+func TestBrierScore(t *testing.T) {
+	t.Parallel()
+
+	if math.Abs(BrierScore(0.8, predictions.Right)-0.04) > 0.00001 {
+		t.Errorf("Incorrect Brier score for right prediction; should be %g, was %g", 0.04, BrierScore(0.8, predictions.Right))
+	}
+	if math.Abs(BrierScore(0.8, predictions.Wrong)-0.64) > 0.00001 {
+		t.Errorf("Incorrect Brier score for wrong prediction; should be %g, was %g", 0.64, BrierScore(0.8, predictions.Wrong))
+	}
+	if !math.IsNaN(BrierScore(0.8, predictions.Unknown)) {
+		t.Errorf("Brier score for unjudged prediction should be NaN, was %g", BrierScore(0.8, predictions.Unknown))
+	}
+}
+
+func TestUserSummaries(t *testing.T) {
+	t.Parallel()
+
+	ps := []*predictions.PredictionSummary{
+		{Id: 1, Creator: "jbeshir", CreatorSlug: "jbeshir", Outcome: predictions.Right},
+		{Id: 2, Creator: "jbeshir", CreatorSlug: "jbeshir", Outcome: predictions.Wrong},
+		{Id: 3, Creator: "Michael Dickens", CreatorSlug: "MTGandP", Outcome: predictions.Unknown},
+	}
+	responses := []*predictions.PredictionResponse{
+		{Prediction: 1, User: "jbeshir", UserSlug: "jbeshir", Confidence: 0.7, Kind: predictions.WagerOnly},
+		{Prediction: 2, User: "jbeshir", UserSlug: "jbeshir", Confidence: 0.3, Kind: predictions.WagerAndComment},
+		{Prediction: 3, User: "jbeshir", UserSlug: "jbeshir", Confidence: 0.9, Kind: predictions.WagerOnly},
+		{Prediction: 1, User: "Michael Dickens", UserSlug: "MTGandP", Confidence: math.NaN(), Kind: predictions.CommentOnly},
+	}
+
+	summaries := UserSummaries(ps, responses)
+	if len(summaries) != 2 {
+		t.Fatalf("Incorrect number of summaries; should be %d, was %d", 2, len(summaries))
+	}
+
+	if summaries[0].Slug != "MTGandP" || summaries[0].Wagers != 0 || summaries[0].Predictions != 1 {
+		t.Errorf("Incorrect first summary; should be MTGandP with 1 prediction and no wagers, was %+v", summaries[0])
+	}
+	if !math.IsNaN(summaries[0].BrierScore) {
+		t.Errorf("Brier score without judged wagers should be NaN, was %g", summaries[0].BrierScore)
+	}
+
+	s := summaries[1]
+	if s.Predictions != 2 || s.Wagers != 3 || s.JudgedWagers != 2 {
+		t.Errorf("Incorrect counts; should be 2 predictions, 3 wagers, 2 judged, was %+v", s)
+	}
+	if math.Abs(s.BrierScore-0.09) > 0.00001 {
+		t.Errorf("Incorrect Brier score; should be %g, was %g", 0.09, s.BrierScore)
+	}
+	if s.Calibration[2].Count != 2 || s.Calibration[2].Right != 2 {
+		t.Errorf("Incorrect 70%% calibration bucket; should have 2 of 2 right, was %+v", s.Calibration[2])
+	}
+	if !math.IsNaN(s.Calibration[0].Accuracy) {
+		t.Errorf("Empty calibration bucket accuracy should be NaN, was %g", s.Calibration[0].Accuracy)
+	}
+}
@@ -0,0 +1,182 @@
+package search
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"math"
+	"sort"
+)
+
+// titleWeight is how much more a match in a prediction's title counts than one in a comment.
+const titleWeight = 3
+
+// Index is an in-memory inverted index over prediction titles and response comments.
+// Each prediction is one document, with its comments searched alongside its title.
+type Index struct {
+	docs     []*document
+	postings map[string]map[int]*frequency
+}
+
+type document struct {
+	prediction *predictions.PredictionSummary
+	responses  []*predictions.PredictionResponse
+	title      []string
+	// comments holds the words of each response's comment, by position in responses.
+	comments [][]string
+}
+
+type frequency struct {
+	title   int
+	comment int
+}
+
+// Result is a prediction matching a search, with the responses whose comments matched.
+type Result struct {
+	Prediction *predictions.PredictionSummary
+	Responses  []*predictions.PredictionResponse
+	Score      float64
+}
+
+func NewIndex(ps []*predictions.PredictionSummary, responses []*predictions.PredictionResponse) *Index {
+	idx := &Index{
+		postings: make(map[string]map[int]*frequency),
+	}
+
+	byPrediction := make(map[int64]*document)
+	for _, p := range ps {
+		d := &document{
+			prediction: p,
+			title:      tokenize(p.Title),
+		}
+		byPrediction[p.Id] = d
+		idx.docs = append(idx.docs, d)
+	}
+	for _, r := range responses {
+		d, exists := byPrediction[r.Prediction]
+		if !exists {
+			continue
+		}
+		d.responses = append(d.responses, r)
+		d.comments = append(d.comments, tokenize(r.Comment))
+	}
+
+	for i, d := range idx.docs {
+		for _, word := range d.title {
+			idx.frequency(word, i).title++
+		}
+		for _, comment := range d.comments {
+			for _, word := range comment {
+				idx.frequency(word, i).comment++
+			}
+		}
+	}
+
+	return idx
+}
+
+func (idx *Index) frequency(word string, doc int) *frequency {
+	postings, exists := idx.postings[word]
+	if !exists {
+		postings = make(map[int]*frequency)
+		idx.postings[word] = postings
+	}
+	f, exists := postings[doc]
+	if !exists {
+		f = new(frequency)
+		postings[doc] = f
+	}
+	return f
+}
+
+// Search returns the predictions matching the query and filter, best matches first.
+// Matches are ranked by TF-IDF over the query's words, with title matches weighted above
+// comment matches; equally ranked predictions are ordered newest first.
+func (idx *Index) Search(q string, filter *predictions.Filter) ([]*Result, error) {
+	parsed, err := parseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := parsed.terms()
+	var results []*Result
+	for doc := range parsed.docs(idx) {
+		d := idx.docs[doc]
+		if filter != nil && !filter.Matches(d.prediction) {
+			continue
+		}
+
+		results = append(results, &Result{
+			Prediction: d.prediction,
+			Responses:  d.matchingResponses(terms),
+			Score:      idx.score(doc, terms),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Prediction.Id > results[j].Prediction.Id
+	})
+	return results, nil
+}
+
+func (idx *Index) score(doc int, terms []string) (score float64) {
+	for _, term := range terms {
+		f, exists := idx.postings[term][doc]
+		if !exists {
+			continue
+		}
+
+		idf := math.Log(1 + float64(len(idx.docs))/float64(len(idx.postings[term])))
+		score += idf * (titleWeight*math.Log(1+float64(f.title)) + math.Log(1+float64(f.comment)))
+	}
+	return
+}
+
+func (d *document) containsPhrase(words []string) bool {
+	if containsSequence(d.title, words) {
+		return true
+	}
+	for _, comment := range d.comments {
+		if containsSequence(comment, words) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *document) matchingResponses(terms []string) (matching []*predictions.PredictionResponse) {
+	for i, comment := range d.comments {
+		if containsAny(comment, terms) {
+			matching = append(matching, d.responses[i])
+		}
+	}
+	return
+}
+
+func containsSequence(words, sequence []string) bool {
+	for i := 0; i+len(sequence) <= len(words); i++ {
+		matched := true
+		for j, word := range sequence {
+			if words[i+j] != word {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(words, terms []string) bool {
+	for _, word := range words {
+		for _, term := range terms {
+			if word == term {
+				return true
+			}
+		}
+	}
+	return false
+}
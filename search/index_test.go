@@ -0,0 +1,144 @@
+package search
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"math"
+	"testing"
+	"time"
+)
+
+func testIndex() *Index {
+	return NewIndex([]*predictions.PredictionSummary{
+		{
+			Id:          1,
+			Title:       "The UK will leave the European Union by 2019",
+			CreatorSlug: "jbeshir",
+			Created:     time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+			Outcome:     predictions.Wrong,
+		},
+		{
+			Id:          2,
+			Title:       "A self-driving car will be sold in the UK",
+			CreatorSlug: "MTGandP",
+			Created:     time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Id:          3,
+			Title:       "Bitcoin will pass $20,000",
+			CreatorSlug: "jbeshir",
+			Created:     time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC),
+			Outcome:     predictions.Right,
+		},
+	}, []*predictions.PredictionResponse{
+		{Prediction: 3, Confidence: 0.4, Kind: predictions.WagerAndComment, Comment: "The European Union might ban it"},
+		{Prediction: 3, Confidence: math.NaN(), Kind: predictions.CommentOnly, Comment: "Union rules"},
+		{Prediction: 2, Confidence: 0.6, Kind: predictions.WagerOnly},
+	})
+}
+
+func resultIds(results []*Result) (ids []int64) {
+	for _, r := range results {
+		ids = append(ids, r.Prediction.Id)
+	}
+	return
+}
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+
+	idx := testIndex()
+	tests := []struct {
+		query string
+		ids   []int64
+	}{
+		{"uk", []int64{2, 1}},
+		{"european union", []int64{1, 3}},
+		{`"european union"`, []int64{1, 3}},
+		{`"union european"`, nil},
+		{"uk AND union", []int64{1}},
+		{"bitcoin OR car", []int64{3, 2}},
+		{"uk -union", []int64{2}},
+		{"NOT uk", []int64{3}},
+		{"(bitcoin OR car) NOT self-driving", []int64{3}},
+		{"20,000", []int64{3}},
+		{"mars", nil},
+	}
+
+	for _, test := range tests {
+		results, err := idx.Search(test.query, nil)
+		if err != nil {
+			t.Errorf("Error searching for %s: %s", test.query, err)
+			continue
+		}
+
+		ids := resultIds(results)
+		if len(ids) != len(test.ids) {
+			t.Errorf("Incorrect result count for %s; should be %d, was %d", test.query, len(test.ids), len(ids))
+			continue
+		}
+		for i := range ids {
+			if ids[i] != test.ids[i] {
+				t.Errorf("Incorrect results for %s; should be %v, was %v", test.query, test.ids, ids)
+				break
+			}
+		}
+	}
+}
+
+func TestSearchMatchingResponses(t *testing.T) {
+	t.Parallel()
+
+	results, err := testIndex().Search("union", nil)
+	if err != nil {
+		t.Fatalf("Error searching: %s", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Incorrect result count; should be %d, was %d", 2, len(results))
+	}
+	if len(results[0].Responses) != 0 {
+		t.Errorf("Incorrect matching responses for title match; should be %d, was %d", 0, len(results[0].Responses))
+	}
+	if len(results[1].Responses) != 2 {
+		t.Errorf("Incorrect matching responses for comment match; should be %d, was %d", 2, len(results[1].Responses))
+	}
+}
+
+func TestSearchFiltered(t *testing.T) {
+	t.Parallel()
+
+	idx := testIndex()
+	tests := []struct {
+		filter *predictions.Filter
+		ids    []int64
+	}{
+		{&predictions.Filter{Creator: "jbeshir"}, []int64{1, 3}},
+		{&predictions.Filter{Outcome: "right"}, []int64{3}},
+		{&predictions.Filter{Outcome: "unknown"}, nil},
+		{&predictions.Filter{CreatedAfter: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)}, []int64{3}},
+		{&predictions.Filter{CreatedBefore: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)}, []int64{1}},
+	}
+
+	for i, test := range tests {
+		results, err := idx.Search("union", test.filter)
+		if err != nil {
+			t.Fatalf("Error searching: %s", err)
+		}
+
+		ids := resultIds(results)
+		if len(ids) != len(test.ids) {
+			t.Errorf("Incorrect result count for filter %d; should be %d, was %d", i, len(test.ids), len(ids))
+		}
+	}
+}
+
+func TestSearchInvalidQuery(t *testing.T) {
+	t.Parallel()
+
+	idx := testIndex()
+	for _, query := range []string{"", `"unterminated`, "(uk", "uk)", "uk NOT"} {
+		_, err := idx.Search(query, nil)
+		if err == nil {
+			t.Errorf("Expected error parsing query %q", query)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// query is a node of a parsed search query, evaluated against the index to the set of
+// matching documents.
+type query interface {
+	docs(idx *Index) map[int]bool
+	// terms returns the terms a document should be ranked by, excluding negated ones.
+	terms() []string
+}
+
+type termQuery struct {
+	term string
+}
+
+type phraseQuery struct {
+	words []string
+}
+
+type andQuery struct {
+	clauses []query
+}
+
+type orQuery struct {
+	clauses []query
+}
+
+type notQuery struct {
+	clause query
+}
+
+type tokenKind int
+
+const (
+	wordToken tokenKind = iota
+	phraseToken
+	openToken
+	closeToken
+	notToken
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// parseQuery parses a search query. Words must all appear unless separated by OR,
+// "quoted phrases" must appear in order in the same title or comment, NOT or a leading
+// - excludes matches, and parentheses group clauses. AND may be written but is implied.
+func parseQuery(s string) (query, error) {
+	tokens, err := lexQuery(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, errors.New("unexpected closing parenthesis")
+	}
+	if q == nil {
+		return nil, errors.New("empty query")
+	}
+	return q, nil
+}
+
+func lexQuery(s string) (tokens []token, err error) {
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: openToken})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: closeToken})
+			i++
+		case r == '-':
+			tokens = append(tokens, token{kind: notToken})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, errors.New("unterminated quoted phrase")
+			}
+			tokens = append(tokens, token{kind: phraseToken, text: string(runes[i+1 : end])})
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' && runes[end] != '"' {
+				end++
+			}
+			word := string(runes[i:end])
+			if word == "NOT" {
+				tokens = append(tokens, token{kind: notToken})
+			} else {
+				tokens = append(tokens, token{kind: wordToken, text: word})
+			}
+			i = end
+		}
+	}
+	return
+}
+
+type queryParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *queryParser) peek() *token {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *queryParser) parseOr() (query, error) {
+	var clauses []query
+	for {
+		clause, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if clause != nil {
+			clauses = append(clauses, clause)
+		}
+
+		t := p.peek()
+		if t == nil || t.kind != wordToken || t.text != "OR" {
+			break
+		}
+		p.pos++
+	}
+
+	switch len(clauses) {
+	case 0:
+		return nil, nil
+	case 1:
+		return clauses[0], nil
+	default:
+		return &orQuery{clauses: clauses}, nil
+	}
+}
+
+func (p *queryParser) parseAnd() (query, error) {
+	var clauses []query
+	for {
+		t := p.peek()
+		if t == nil || t.kind == closeToken || (t.kind == wordToken && t.text == "OR") {
+			break
+		}
+		if t.kind == wordToken && t.text == "AND" {
+			p.pos++
+			continue
+		}
+
+		clause, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if clause != nil {
+			clauses = append(clauses, clause)
+		}
+	}
+
+	switch len(clauses) {
+	case 0:
+		return nil, nil
+	case 1:
+		return clauses[0], nil
+	default:
+		return &andQuery{clauses: clauses}, nil
+	}
+}
+
+func (p *queryParser) parseUnary() (query, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, errors.New("expected a search term")
+	}
+	p.pos++
+
+	switch t.kind {
+	case notToken:
+		clause, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if clause == nil {
+			return nil, nil
+		}
+		return &notQuery{clause: clause}, nil
+	case openToken:
+		clause, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		t := p.peek()
+		if t == nil || t.kind != closeToken {
+			return nil, errors.New("unclosed parenthesis")
+		}
+		p.pos++
+		return clause, nil
+	default:
+		// Words containing punctuation are treated as the phrase of their parts,
+		// so e.g. U.S. matches as it was tokenized when indexed.
+		words := tokenize(t.text)
+		switch len(words) {
+		case 0:
+			return nil, nil
+		case 1:
+			return &termQuery{term: words[0]}, nil
+		default:
+			return &phraseQuery{words: words}, nil
+		}
+	}
+}
+
+func (q *termQuery) docs(idx *Index) map[int]bool {
+	matched := make(map[int]bool)
+	for doc := range idx.postings[q.term] {
+		matched[doc] = true
+	}
+	return matched
+}
+
+func (q *termQuery) terms() []string {
+	return []string{q.term}
+}
+
+func (q *phraseQuery) docs(idx *Index) map[int]bool {
+	matched := (&termQuery{term: q.words[0]}).docs(idx)
+	for _, word := range q.words[1:] {
+		postings := idx.postings[word]
+		for doc := range matched {
+			if _, exists := postings[doc]; !exists {
+				delete(matched, doc)
+			}
+		}
+	}
+
+	for doc := range matched {
+		if !idx.docs[doc].containsPhrase(q.words) {
+			delete(matched, doc)
+		}
+	}
+	return matched
+}
+
+func (q *phraseQuery) terms() []string {
+	return q.words
+}
+
+func (q *andQuery) docs(idx *Index) map[int]bool {
+	var matched map[int]bool
+	var excluded []query
+	for _, clause := range q.clauses {
+		if not, ok := clause.(*notQuery); ok {
+			excluded = append(excluded, not.clause)
+			continue
+		}
+
+		clauseDocs := clause.docs(idx)
+		if matched == nil {
+			matched = clauseDocs
+			continue
+		}
+		for doc := range matched {
+			if !clauseDocs[doc] {
+				delete(matched, doc)
+			}
+		}
+	}
+
+	// A conjunction of only exclusions matches everything else
+	if matched == nil {
+		matched = allDocs(idx)
+	}
+	for _, clause := range excluded {
+		for doc := range clause.docs(idx) {
+			delete(matched, doc)
+		}
+	}
+	return matched
+}
+
+func (q *andQuery) terms() (terms []string) {
+	for _, clause := range q.clauses {
+		terms = append(terms, clause.terms()...)
+	}
+	return
+}
+
+func (q *orQuery) docs(idx *Index) map[int]bool {
+	matched := make(map[int]bool)
+	for _, clause := range q.clauses {
+		for doc := range clause.docs(idx) {
+			matched[doc] = true
+		}
+	}
+	return matched
+}
+
+func (q *orQuery) terms() (terms []string) {
+	for _, clause := range q.clauses {
+		terms = append(terms, clause.terms()...)
+	}
+	return
+}
+
+func (q *notQuery) docs(idx *Index) map[int]bool {
+	matched := allDocs(idx)
+	for doc := range q.clause.docs(idx) {
+		delete(matched, doc)
+	}
+	return matched
+}
+
+func (q *notQuery) terms() []string {
+	return nil
+}
+
+func allDocs(idx *Index) map[int]bool {
+	matched := make(map[int]bool)
+	for doc := range idx.docs {
+		matched[doc] = true
+	}
+	return matched
+}
+
+// tokenize splits text into lowercase words of letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
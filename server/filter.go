@@ -0,0 +1,34 @@
+package server
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"time"
+)
+
+func parsePredictionFilter(query map[string][]string) (f *predictions.Filter, err error) {
+	f = &predictions.Filter{
+		Creator: firstValue(query, "creator"),
+		Outcome: firstValue(query, "outcome"),
+		Text:    firstValue(query, "q"),
+	}
+
+	switch f.Outcome {
+	case "", "right", "wrong", "unknown":
+	default:
+		return nil, &paramError{"outcome", f.Outcome}
+	}
+
+	for name, t := range map[string]*time.Time{
+		"created_after":   &f.CreatedAfter,
+		"created_before":  &f.CreatedBefore,
+		"deadline_after":  &f.DeadlineAfter,
+		"deadline_before": &f.DeadlineBefore,
+	} {
+		*t, err = parseTimeParam(query, name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
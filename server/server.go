@@ -0,0 +1,263 @@
+package server
+
+import (
+	"encoding/json"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"github.com/jbeshir/predictionbook-extractor/scoring"
+	"github.com/jbeshir/predictionbook-extractor/store"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// Server is a read-only JSON API over a stored dataset.
+type Server struct {
+	dataset     *store.Dataset
+	byId        map[int64]*predictions.PredictionSummary
+	responses   map[int64][]*predictions.PredictionResponse
+	users       []*scoring.UserSummary
+	usersBySlug map[string]*scoring.UserSummary
+	mux         *http.ServeMux
+}
+
+type predictionListResponse struct {
+	Total       int                       `json:"total"`
+	Predictions []*store.PredictionRecord `json:"predictions"`
+}
+
+type predictionResponse struct {
+	Prediction *store.PredictionRecord  `json:"prediction"`
+	Responses  []*store.ResponseRecord  `json:"responses"`
+	Judgements []*store.JudgementRecord `json:"judgements"`
+}
+
+type userRecord struct {
+	Slug         string               `json:"slug"`
+	Name         string               `json:"name"`
+	Predictions  int64                `json:"predictions"`
+	Wagers       int64                `json:"wagers"`
+	JudgedWagers int64                `json:"judged_wagers"`
+	BrierScore   *float64             `json:"brier_score"`
+	Calibration  []*calibrationRecord `json:"calibration"`
+}
+
+type calibrationRecord struct {
+	Confidence float64  `json:"confidence"`
+	Count      int64    `json:"count"`
+	Right      int64    `json:"right"`
+	Accuracy   *float64 `json:"accuracy"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func NewServer(dataset *store.Dataset) *Server {
+	s := &Server{
+		dataset:     dataset,
+		byId:        make(map[int64]*predictions.PredictionSummary),
+		responses:   make(map[int64][]*predictions.PredictionResponse),
+		users:       scoring.UserSummaries(dataset.Predictions, dataset.Responses),
+		usersBySlug: make(map[string]*scoring.UserSummary),
+		mux:         http.NewServeMux(),
+	}
+
+	for _, p := range dataset.Predictions {
+		s.byId[p.Id] = p
+	}
+	for _, r := range dataset.Responses {
+		s.responses[r.Prediction] = append(s.responses[r.Prediction], r)
+	}
+	for _, u := range s.users {
+		s.usersBySlug[u.Slug] = u
+	}
+
+	s.mux.HandleFunc("/api/predictions", s.handlePredictions)
+	s.mux.HandleFunc("/api/predictions/", s.handlePrediction)
+	s.mux.HandleFunc("/api/users", s.handleUsers)
+	s.mux.HandleFunc("/api/users/", s.handleUser)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handlePredictions(w http.ResponseWriter, r *http.Request) {
+	filter, err := parsePredictionFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, offset, err := parsePage(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var matched []*predictions.PredictionSummary
+	for _, p := range s.dataset.Predictions {
+		if filter.Matches(p) {
+			matched = append(matched, p)
+		}
+	}
+
+	resp := &predictionListResponse{
+		Total:       len(matched),
+		Predictions: []*store.PredictionRecord{},
+	}
+	for i := offset; i < len(matched) && i < offset+limit; i++ {
+		resp.Predictions = append(resp.Predictions, store.NewPredictionRecord(matched[i]))
+	}
+
+	writeJson(w, http.StatusOK, resp)
+}
+
+func (s *Server) handlePrediction(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(r.URL.Path, "/api/predictions/"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "prediction not found")
+		return
+	}
+
+	p, exists := s.byId[id]
+	if !exists {
+		writeError(w, http.StatusNotFound, "prediction not found")
+		return
+	}
+
+	record := store.NewPredictionRecord(p)
+	resp := &predictionResponse{
+		Prediction: record,
+		Responses:  []*store.ResponseRecord{},
+		Judgements: record.Judgements,
+	}
+	if resp.Judgements == nil {
+		resp.Judgements = []*store.JudgementRecord{}
+	}
+	for _, response := range s.responses[id] {
+		resp.Responses = append(resp.Responses, store.NewResponseRecord(response))
+	}
+
+	writeJson(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePage(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users := []*userRecord{}
+	for i := offset; i < len(s.users) && i < offset+limit; i++ {
+		users = append(users, newUserRecord(s.users[i]))
+	}
+
+	writeJson(w, http.StatusOK, users)
+}
+
+func (s *Server) handleUser(w http.ResponseWriter, r *http.Request) {
+	u, exists := s.usersBySlug[strings.TrimPrefix(r.URL.Path, "/api/users/")]
+	if !exists {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	writeJson(w, http.StatusOK, newUserRecord(u))
+}
+
+func newUserRecord(u *scoring.UserSummary) *userRecord {
+	record := &userRecord{
+		Slug:         u.Slug,
+		Name:         u.Name,
+		Predictions:  u.Predictions,
+		Wagers:       u.Wagers,
+		JudgedWagers: u.JudgedWagers,
+		BrierScore:   store.NullableFloat(u.BrierScore),
+	}
+	for _, b := range u.Calibration {
+		record.Calibration = append(record.Calibration, &calibrationRecord{
+			Confidence: b.Confidence,
+			Count:      b.Count,
+			Right:      b.Right,
+			Accuracy:   store.NullableFloat(b.Accuracy),
+		})
+	}
+
+	return record
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJson(w, status, &errorResponse{Error: message})
+}
+
+func parsePage(query map[string][]string) (limit, offset int, err error) {
+	limit = defaultLimit
+	if v := firstValue(query, "limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return 0, 0, &paramError{"limit", v}
+		}
+	}
+
+	if v := firstValue(query, "offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return 0, 0, &paramError{"offset", v}
+		}
+	}
+
+	return limit, offset, nil
+}
+
+func parseTimeParam(query map[string][]string, name string) (t time.Time, err error) {
+	v := firstValue(query, name)
+	if v == "" {
+		return
+	}
+
+	t, err = time.Parse(time.RFC3339, v)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", v)
+	}
+	if err != nil {
+		return t, &paramError{name, v}
+	}
+	return
+}
+
+func firstValue(query map[string][]string, name string) string {
+	if vs := query[name]; len(vs) > 0 {
+		return vs[0]
+	}
+	return ""
+}
+
+type paramError struct {
+	name  string
+	value string
+}
+
+func (e *paramError) Error() string {
+	return "invalid " + e.name + ": " + e.value
+}
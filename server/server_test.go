@@ -0,0 +1,152 @@
+package server
+
+import (
+	"encoding/json"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"github.com/jbeshir/predictionbook-extractor/store"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testServer() *Server {
+	return NewServer(&store.Dataset{
+		BaseUrl: "https://example.org",
+		Predictions: []*predictions.PredictionSummary{
+			{
+				Id:          1,
+				Title:       "The election will be held in May",
+				Creator:     "jbeshir",
+				CreatorSlug: "jbeshir",
+				Created:     time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+				Deadline:    time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC),
+				Outcome:     predictions.Right,
+				Judgements: []*predictions.PredictionJudgement{
+					{Prediction: 1, UserSlug: "jbeshir", Outcome: predictions.Right},
+				},
+			},
+			{
+				Id:             2,
+				Title:          "I will finish the book",
+				Creator:        "Michael Dickens",
+				CreatorSlug:    "MTGandP",
+				Created:        time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC),
+				Deadline:       time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+				MeanConfidence: math.NaN(),
+			},
+		},
+		Responses: []*predictions.PredictionResponse{
+			{Prediction: 1, User: "jbeshir", UserSlug: "jbeshir", Confidence: 0.8, Kind: predictions.WagerOnly},
+			{Prediction: 2, User: "jbeshir", UserSlug: "jbeshir", Confidence: math.NaN(), Kind: predictions.CommentOnly},
+		},
+	})
+}
+
+func testGet(t *testing.T, s *Server, url string, v interface{}) int {
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+
+	err := json.Unmarshal(recorder.Body.Bytes(), v)
+	if err != nil {
+		t.Fatalf("Couldn't decode response for %s: %s", url, err)
+	}
+	return recorder.Code
+}
+
+func TestListPredictionsFiltered(t *testing.T) {
+	t.Parallel()
+
+	s := testServer()
+	tests := []struct {
+		url string
+		ids []int64
+	}{
+		{"/api/predictions", []int64{1, 2}},
+		{"/api/predictions?creator=MTGandP", []int64{2}},
+		{"/api/predictions?creator=jbeshir&outcome=right", []int64{1}},
+		{"/api/predictions?outcome=unknown", []int64{2}},
+		{"/api/predictions?created_after=2018-02-01", []int64{2}},
+		{"/api/predictions?deadline_before=2018-12-01T00:00:00Z", []int64{1}},
+		{"/api/predictions?q=ELECTION", []int64{1}},
+		{"/api/predictions?limit=1&offset=1", []int64{2}},
+	}
+
+	for _, test := range tests {
+		var resp predictionListResponse
+		code := testGet(t, s, test.url, &resp)
+		if code != http.StatusOK {
+			t.Errorf("Incorrect status for %s; should be %d, was %d", test.url, http.StatusOK, code)
+			continue
+		}
+		if len(resp.Predictions) != len(test.ids) {
+			t.Errorf("Incorrect number of predictions for %s; should be %d, was %d", test.url, len(test.ids), len(resp.Predictions))
+			continue
+		}
+		for i, id := range test.ids {
+			if resp.Predictions[i].Id != id {
+				t.Errorf("Incorrect prediction %d for %s; should be %d, was %d", i, test.url, id, resp.Predictions[i].Id)
+			}
+		}
+	}
+}
+
+func TestListPredictionsInvalid(t *testing.T) {
+	t.Parallel()
+
+	s := testServer()
+	for _, url := range []string{"/api/predictions?outcome=maybe", "/api/predictions?created_after=yesterday", "/api/predictions?limit=0"} {
+		var resp errorResponse
+		code := testGet(t, s, url, &resp)
+		if code != http.StatusBadRequest || resp.Error == "" {
+			t.Errorf("Incorrect response for %s; should be %d with an error, was %d with %+v", url, http.StatusBadRequest, code, resp)
+		}
+	}
+}
+
+func TestGetPrediction(t *testing.T) {
+	t.Parallel()
+
+	s := testServer()
+	var resp predictionResponse
+	code := testGet(t, s, "/api/predictions/1", &resp)
+	if code != http.StatusOK {
+		t.Fatalf("Incorrect status; should be %d, was %d", http.StatusOK, code)
+	}
+	if resp.Prediction.Id != 1 || len(resp.Responses) != 1 || len(resp.Judgements) != 1 {
+		t.Errorf("Incorrect prediction response; should be prediction 1 with 1 response and 1 judgement, was %+v", resp)
+	}
+
+	var errResp errorResponse
+	code = testGet(t, s, "/api/predictions/3", &errResp)
+	if code != http.StatusNotFound {
+		t.Errorf("Incorrect status for missing prediction; should be %d, was %d", http.StatusNotFound, code)
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	t.Parallel()
+
+	s := testServer()
+	var users []*userRecord
+	code := testGet(t, s, "/api/users", &users)
+	if code != http.StatusOK || len(users) != 2 {
+		t.Fatalf("Incorrect users response; should be %d with 2 users, was %d with %d", http.StatusOK, code, len(users))
+	}
+
+	var user userRecord
+	code = testGet(t, s, "/api/users/jbeshir", &user)
+	if code != http.StatusOK {
+		t.Fatalf("Incorrect status; should be %d, was %d", http.StatusOK, code)
+	}
+	if user.Wagers != 1 || user.BrierScore == nil || math.Abs(*user.BrierScore-0.04) > 0.00001 {
+		t.Errorf("Incorrect user; should have 1 wager with Brier score 0.04, was %+v", user)
+	}
+
+	var errResp errorResponse
+	code = testGet(t, s, "/api/users/nobody", &errResp)
+	if code != http.StatusNotFound {
+		t.Errorf("Incorrect status for missing user; should be %d, was %d", http.StatusNotFound, code)
+	}
+}
@@ -0,0 +1,186 @@
+package store
+
+import (
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ChangeKind int64
+
+const (
+	PredictionAdded ChangeKind = iota
+	PredictionRemoved
+	OutcomeChanged
+	TitleEdited
+	DeadlineChanged
+	CreatorChanged
+	ResponseAdded
+)
+
+var changeKinds = []ChangeKind{PredictionAdded, PredictionRemoved, OutcomeChanged, TitleEdited, DeadlineChanged, CreatorChanged, ResponseAdded}
+
+func (k ChangeKind) String() string {
+	switch k {
+	case PredictionAdded:
+		return "prediction_added"
+	case PredictionRemoved:
+		return "prediction_removed"
+	case OutcomeChanged:
+		return "outcome_changed"
+	case TitleEdited:
+		return "title_edited"
+	case DeadlineChanged:
+		return "deadline_changed"
+	case CreatorChanged:
+		return "creator_changed"
+	case ResponseAdded:
+		return "response_added"
+	default:
+		return "unknown"
+	}
+}
+
+var changedFields = map[ChangeKind]string{
+	OutcomeChanged:  "outcome",
+	TitleEdited:     "title",
+	DeadlineChanged: "deadline",
+	CreatorChanged:  "creator",
+}
+
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Change is one difference between two datasets. Old and New hold the changed field's
+// values, and are empty for added and removed predictions; Response is set only for
+// added responses.
+type Change struct {
+	Kind       ChangeKind      `json:"kind"`
+	Prediction int64           `json:"prediction"`
+	Old        string          `json:"old,omitempty"`
+	New        string          `json:"new,omitempty"`
+	Response   *ResponseRecord `json:"response,omitempty"`
+}
+
+func (c *Change) String() string {
+	switch c.Kind {
+	case PredictionAdded:
+		return fmt.Sprintf("#%d added: %s", c.Prediction, c.New)
+	case PredictionRemoved:
+		return fmt.Sprintf("#%d disappeared: %s", c.Prediction, c.Old)
+	case ResponseAdded:
+		return fmt.Sprintf("#%d new response by %s at %s", c.Prediction, c.Response.User, c.Response.Time.UTC().Format(time.RFC3339))
+	default:
+		return fmt.Sprintf("#%d %s changed: %q -> %q", c.Prediction, changedFields[c.Kind], c.Old, c.New)
+	}
+}
+
+// Diff compares two datasets, returning changes ordered by prediction id, then kind.
+// Responses are matched by prediction, time and user; responses which disappear aren't
+// reported, as the site only removes them along with their prediction.
+func Diff(previous, current *Dataset) (changes []*Change) {
+	oldById := make(map[int64]*predictions.PredictionSummary)
+	for _, p := range previous.Predictions {
+		oldById[p.Id] = p
+	}
+	newById := make(map[int64]*predictions.PredictionSummary)
+	for _, p := range current.Predictions {
+		newById[p.Id] = p
+	}
+
+	for _, p := range previous.Predictions {
+		if newById[p.Id] == nil {
+			changes = append(changes, &Change{Kind: PredictionRemoved, Prediction: p.Id, Old: p.Title})
+		}
+	}
+	for _, n := range current.Predictions {
+		o := oldById[n.Id]
+		if o == nil {
+			changes = append(changes, &Change{Kind: PredictionAdded, Prediction: n.Id, New: n.Title})
+			continue
+		}
+
+		if o.Outcome != n.Outcome {
+			changes = append(changes, &Change{Kind: OutcomeChanged, Prediction: n.Id, Old: o.Outcome.String(), New: n.Outcome.String()})
+		}
+		if o.Title != n.Title {
+			changes = append(changes, &Change{Kind: TitleEdited, Prediction: n.Id, Old: o.Title, New: n.Title})
+		}
+		if !o.Deadline.Equal(n.Deadline) {
+			changes = append(changes, &Change{Kind: DeadlineChanged, Prediction: n.Id, Old: formatChangeTime(o.Deadline), New: formatChangeTime(n.Deadline)})
+		}
+		// CSV exports have no slugs, so only compare them when both datasets do
+		if o.Creator != n.Creator || (o.CreatorSlug != "" && n.CreatorSlug != "" && o.CreatorSlug != n.CreatorSlug) {
+			changes = append(changes, &Change{Kind: CreatorChanged, Prediction: n.Id, Old: creatorLabel(o), New: creatorLabel(n)})
+		}
+	}
+
+	type responseKey struct {
+		prediction int64
+		time       int64
+		user       string
+	}
+	oldResponses := make(map[responseKey]bool)
+	for _, r := range previous.Responses {
+		oldResponses[responseKey{r.Prediction, r.Time.Unix(), r.User}] = true
+	}
+	for _, r := range current.Responses {
+		if !oldResponses[responseKey{r.Prediction, r.Time.Unix(), r.User}] {
+			changes = append(changes, &Change{Kind: ResponseAdded, Prediction: r.Prediction, Response: NewResponseRecord(r)})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Prediction != changes[j].Prediction {
+			return changes[i].Prediction < changes[j].Prediction
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+	return changes
+}
+
+// CountChanges returns the number of changes of each kind.
+func CountChanges(changes []*Change) map[ChangeKind]int {
+	counts := make(map[ChangeKind]int)
+	for _, c := range changes {
+		counts[c.Kind]++
+	}
+	return counts
+}
+
+// SummariseChanges describes the number of changes of each kind, in a fixed order.
+func SummariseChanges(changes []*Change) string {
+	counts := CountChanges(changes)
+	descriptions := map[ChangeKind]string{
+		PredictionAdded:   "new predictions",
+		PredictionRemoved: "disappeared predictions",
+		OutcomeChanged:    "outcome changes",
+		TitleEdited:       "title edits",
+		DeadlineChanged:   "deadline changes",
+		CreatorChanged:    "creator changes",
+		ResponseAdded:     "new responses",
+	}
+
+	var lines []string
+	for _, kind := range changeKinds {
+		lines = append(lines, fmt.Sprintf("%d %s", counts[kind], descriptions[kind]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatChangeTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func creatorLabel(p *predictions.PredictionSummary) string {
+	if p.CreatorSlug == "" {
+		return p.Creator
+	}
+	return p.Creator + " (" + p.CreatorSlug + ")"
+}
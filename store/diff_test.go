@@ -0,0 +1,118 @@
+package store
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	previous := &Dataset{
+		Predictions: []*predictions.PredictionSummary{
+			{Id: 1, Title: "Removed", Creator: "Alice"},
+			{Id: 2, Title: "Before", Creator: "Alice", Deadline: time.Unix(1000, 0)},
+			{Id: 3, Title: "Unchanged", Creator: "Bob", CreatorSlug: "bob"},
+		},
+		Responses: []*predictions.PredictionResponse{
+			{Prediction: 2, Time: time.Unix(100, 0), User: "Alice"},
+		},
+	}
+	current := &Dataset{
+		Predictions: []*predictions.PredictionSummary{
+			{Id: 2, Title: "After", Creator: "Carol", Deadline: time.Unix(2000, 0), Outcome: predictions.Right},
+			// Slugs missing from one dataset, as in CSV exports, aren't a change
+			{Id: 3, Title: "Unchanged", Creator: "Bob"},
+			{Id: 4, Title: "Added", Creator: "Bob"},
+		},
+		Responses: []*predictions.PredictionResponse{
+			{Prediction: 2, Time: time.Unix(100, 0), User: "Alice"},
+			{Prediction: 2, Time: time.Unix(200, 0), User: "Bob"},
+			{Prediction: 4, Time: time.Unix(300, 0), User: "Bob"},
+		},
+	}
+
+	changes := Diff(previous, current)
+	expected := []struct {
+		kind       ChangeKind
+		prediction int64
+		old, new   string
+	}{
+		{PredictionRemoved, 1, "Removed", ""},
+		{OutcomeChanged, 2, "unknown", "right"},
+		{TitleEdited, 2, "Before", "After"},
+		{DeadlineChanged, 2, "1970-01-01T00:16:40Z", "1970-01-01T00:33:20Z"},
+		{CreatorChanged, 2, "Alice", "Carol"},
+		{ResponseAdded, 2, "", ""},
+		{PredictionAdded, 4, "", "Added"},
+		{ResponseAdded, 4, "", ""},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Incorrect number of changes; should be %d, was %d: %v", len(expected), len(changes), changes)
+	}
+	for i, e := range expected {
+		c := changes[i]
+		if c.Kind != e.kind || c.Prediction != e.prediction || c.Old != e.old || c.New != e.new {
+			t.Errorf("Incorrect change %d; should be %s of #%d from %q to %q, was %s", i, e.kind, e.prediction, e.old, e.new, c)
+		}
+	}
+	if changes[5].Response == nil || changes[5].Response.User != "Bob" {
+		t.Errorf("Incorrect added response, was %+v", changes[5].Response)
+	}
+
+	counts := CountChanges(changes)
+	if counts[ResponseAdded] != 2 || counts[TitleEdited] != 1 {
+		t.Errorf("Incorrect change counts, were %v", counts)
+	}
+}
+
+func TestLoadFileCsv(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatalf("Couldn't create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	predictionsPath := filepath.Join(dir, "predictions.csv")
+	responsesPath := filepath.Join(dir, "responses.csv")
+	sqlitePath := filepath.Join(dir, "dataset.db")
+	for path, content := range map[string]string{
+		predictionsPath: "id,title,created,deadline,mean_confidence,outcome,topics,duplicate_cluster\n" +
+			"5,\"Title, with comma\",1000,1970-01-01T00:33:20Z,,right,politics economics,3\n",
+		responsesPath: "5,1500,0.25,Bob,Hm,Hm,,,wager_and_comment,bob\n",
+		sqlitePath:    "SQLite format 3\x00...",
+	} {
+		err := ioutil.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("Couldn't write %s: %s", path, err)
+		}
+	}
+
+	dataset, err := LoadFile(predictionsPath, responsesPath)
+	if err != nil {
+		t.Fatalf("Error loading should have been nil, was %s", err)
+	}
+	if len(dataset.Predictions) != 1 || len(dataset.Responses) != 1 {
+		t.Fatalf("Incorrect dataset loaded; should have one prediction and response, was %+v", dataset)
+	}
+	p := dataset.Predictions[0]
+	if p.Id != 5 || p.Title != "Title, with comma" || p.Outcome != predictions.Right || !math.IsNaN(p.MeanConfidence) || p.Deadline.Unix() != 2000 || len(p.Topics) != 2 || p.DuplicateCluster != 3 {
+		t.Errorf("Incorrect prediction loaded, was %+v", p)
+	}
+	r := dataset.Responses[0]
+	if r.Prediction != 5 || r.Confidence != 0.25 || r.Kind != predictions.WagerAndComment || r.UserSlug != "bob" {
+		t.Errorf("Incorrect response loaded, was %+v", r)
+	}
+
+	_, err = LoadFile(sqlitePath, "")
+	if err == nil {
+		t.Errorf("Error should have been returned loading an SQLite database")
+	}
+}
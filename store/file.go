@@ -0,0 +1,61 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/export"
+	"os"
+)
+
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// LoadFile loads a dataset from a store directory, a dataset JSON file as kept in one,
+// or a predictions CSV export written with any export options. For CSV exports, responses
+// are read from responsesPath if it is non-empty; CSV exports carry no crawl time or base URL.
+func LoadFile(path, responsesPath string) (*Dataset, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return NewStore(path).Load()
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	start, _ := r.Peek(len(sqliteHeader))
+	if bytes.Equal(start, sqliteHeader) {
+		return nil, errors.New(path + " is an SQLite database, which isn't supported; export it as JSON or CSV")
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(start), []byte("{")) {
+		return readDatasetJson(r)
+	}
+
+	dataset := new(Dataset)
+	dataset.Predictions, err = export.ReadPredictions(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	if responsesPath == "" {
+		return dataset, nil
+	}
+
+	responsesFile, err := os.Open(responsesPath)
+	if err != nil {
+		return nil, err
+	}
+	defer responsesFile.Close()
+
+	dataset.Responses, err = export.ReadResponses(responsesFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", responsesPath, err)
+	}
+	return dataset, nil
+}
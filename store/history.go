@@ -0,0 +1,329 @@
+package store
+
+import (
+	"encoding/json"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const historyFileName = "history.json"
+
+// Fields whose values are versioned in a History.
+const (
+	TitleField          = "title"
+	DeadlineField       = "deadline"
+	OutcomeField        = "outcome"
+	MeanConfidenceField = "mean_confidence"
+	WagerCountField     = "wager_count"
+)
+
+var historyFields = []string{TitleField, DeadlineField, OutcomeField, MeanConfidenceField, WagerCountField}
+
+// RemovedField is versioned "true" when a prediction disappears from the site, and "false"
+// if it reappears.
+const RemovedField = "removed"
+
+// History records every observed value of each prediction's versioned fields, bitemporally:
+// ValidFrom is when the value was first shown on the site, as best we can tell, and
+// ObservedAt is the crawl which first saw it.
+type History struct {
+	Predictions []*PredictionHistory `json:"predictions"`
+
+	byId map[int64]*PredictionHistory
+}
+
+type PredictionHistory struct {
+	Id       int64           `json:"id"`
+	Created  time.Time       `json:"created"`
+	Versions []*FieldVersion `json:"versions"`
+}
+
+// FieldVersion is one value of a field. Values are formatted as text: outcomes by name,
+// deadlines as RFC 3339, and unknown mean confidences as empty.
+type FieldVersion struct {
+	Field      string    `json:"field"`
+	Value      string    `json:"value"`
+	ValidFrom  time.Time `json:"valid_from"`
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+func NewHistory() *History {
+	return &History{byId: make(map[int64]*PredictionHistory)}
+}
+
+// LoadHistory reads the stored history, returning an empty one if nothing has been saved yet.
+func (s *Store) LoadHistory() (*History, error) {
+	content, err := ioutil.ReadFile(filepath.Join(s.dir, historyFileName))
+	if os.IsNotExist(err) {
+		return NewHistory(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	h := NewHistory()
+	err = json.Unmarshal(content, h)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range h.Predictions {
+		h.byId[p.Id] = p
+	}
+	return h, nil
+}
+
+// Prediction returns the history of a single prediction, or nil if it has never been observed.
+func (h *History) Prediction(id int64) *PredictionHistory {
+	return h.byId[id]
+}
+
+// Record adds a version for each field whose value differs from the latest recorded,
+// observed at the dataset's crawl time. Datasets should be recorded in crawl order, and
+// be complete, as predictions missing from one are recorded as removed from the site.
+//
+// The site gives the time some values changed: outcomes at their judgement, and wager
+// counts and mean confidences at the latest wager. Otherwise the first value is taken
+// to be valid from the prediction's creation, and later ones from their observation.
+func (h *History) Record(dataset *Dataset) {
+	observed := dataset.Crawled
+	if observed.IsZero() {
+		observed = time.Now()
+	}
+
+	lastWager := make(map[int64]time.Time)
+	for _, r := range dataset.Responses {
+		if r.Kind.IsWager() && r.Time.After(lastWager[r.Prediction]) {
+			lastWager[r.Prediction] = r.Time
+		}
+	}
+
+	present := make(map[int64]bool)
+	for _, p := range dataset.Predictions {
+		present[p.Id] = true
+		ph := h.byId[p.Id]
+		if ph == nil {
+			ph = &PredictionHistory{Id: p.Id, Created: p.Created}
+			h.byId[p.Id] = ph
+			h.Predictions = append(h.Predictions, ph)
+		}
+		if ph.removed() {
+			ph.Versions = append(ph.Versions, &FieldVersion{
+				Field:      RemovedField,
+				Value:      "false",
+				ValidFrom:  observed.UTC(),
+				ObservedAt: observed.UTC(),
+			})
+		}
+
+		values := versionedValues(p)
+		for _, field := range historyFields {
+			latest := ph.latest(field)
+			if latest != nil && latest.Value == values[field] {
+				continue
+			}
+
+			validFrom := observed
+			if latest == nil && !p.Created.IsZero() {
+				validFrom = p.Created
+			}
+			switch field {
+			case OutcomeField:
+				if judged := lastJudged(p); !judged.IsZero() {
+					validFrom = judged
+				}
+			case MeanConfidenceField, WagerCountField:
+				if wagered := lastWager[p.Id]; !wagered.IsZero() {
+					validFrom = wagered
+				}
+			}
+			if validFrom.After(observed) {
+				validFrom = observed
+			}
+
+			ph.Versions = append(ph.Versions, &FieldVersion{
+				Field:      field,
+				Value:      values[field],
+				ValidFrom:  validFrom.UTC(),
+				ObservedAt: observed.UTC(),
+			})
+		}
+	}
+
+	// We can't tell when a prediction was removed, only that it's gone by this crawl
+	for _, ph := range h.Predictions {
+		if present[ph.Id] || ph.removed() {
+			continue
+		}
+		ph.Versions = append(ph.Versions, &FieldVersion{
+			Field:      RemovedField,
+			Value:      "true",
+			ValidFrom:  observed.UTC(),
+			ObservedAt: observed.UTC(),
+		})
+	}
+
+	sort.Slice(h.Predictions, func(i, j int) bool {
+		return h.Predictions[i].Id < h.Predictions[j].Id
+	})
+}
+
+// removed reports whether the prediction was missing from the latest recorded dataset.
+func (ph *PredictionHistory) removed() bool {
+	latest := ph.latest(RemovedField)
+	return latest != nil && latest.Value == "true"
+}
+
+// latest returns the most recently observed version of a field, or nil if there is none.
+func (ph *PredictionHistory) latest(field string) *FieldVersion {
+	for i := len(ph.Versions) - 1; i >= 0; i-- {
+		if ph.Versions[i].Field == field {
+			return ph.Versions[i]
+		}
+	}
+	return nil
+}
+
+// AsOf reconstructs the versioned fields of every prediction as the site showed them at
+// shown, using only observations made by known, or every observation if known is zero.
+// Predictions created after shown or removed by then are left out, and fields with no
+// version valid by then are left at their zero value, with an unknown mean confidence.
+func (h *History) AsOf(shown, known time.Time) (ps []*predictions.PredictionSummary) {
+	for _, ph := range h.Predictions {
+		if ph.Created.After(shown) {
+			continue
+		}
+
+		current := make(map[string]*FieldVersion)
+		for _, v := range ph.Versions {
+			if v.ValidFrom.After(shown) || (!known.IsZero() && v.ObservedAt.After(known)) {
+				continue
+			}
+			best := current[v.Field]
+			if best == nil || v.ValidFrom.After(best.ValidFrom) || (v.ValidFrom.Equal(best.ValidFrom) && v.ObservedAt.After(best.ObservedAt)) {
+				current[v.Field] = v
+			}
+		}
+		if len(current) == 0 {
+			continue
+		}
+		if removed := current[RemovedField]; removed != nil && removed.Value == "true" {
+			continue
+		}
+
+		p := &predictions.PredictionSummary{
+			Id:             ph.Id,
+			Created:        ph.Created,
+			MeanConfidence: math.NaN(),
+		}
+		for field, v := range current {
+			applyVersion(p, field, v)
+		}
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+func versionedValues(p *predictions.PredictionSummary) map[string]string {
+	values := map[string]string{
+		TitleField:      p.Title,
+		OutcomeField:    p.Outcome.String(),
+		WagerCountField: strconv.FormatInt(p.WagerCount, 10),
+	}
+	if !p.Deadline.IsZero() {
+		values[DeadlineField] = p.Deadline.UTC().Format(time.RFC3339)
+	}
+	if !math.IsNaN(p.MeanConfidence) {
+		values[MeanConfidenceField] = strconv.FormatFloat(p.MeanConfidence, 'f', -1, 64)
+	}
+	return values
+}
+
+func applyVersion(p *predictions.PredictionSummary, field string, v *FieldVersion) {
+	switch field {
+	case TitleField:
+		p.Title = v.Value
+	case DeadlineField:
+		p.Deadline, _ = time.Parse(time.RFC3339, v.Value)
+	case OutcomeField:
+		p.Outcome = parseOutcome(v.Value)
+		if p.Outcome != predictions.Unknown {
+			p.Judged = v.ValidFrom
+		}
+	case MeanConfidenceField:
+		if v.Value != "" {
+			p.MeanConfidence, _ = strconv.ParseFloat(v.Value, 64)
+		}
+	case WagerCountField:
+		p.WagerCount, _ = strconv.ParseInt(v.Value, 10, 64)
+	}
+}
+
+func lastJudged(p *predictions.PredictionSummary) (judged time.Time) {
+	judged = p.Judged
+	for _, j := range p.Judgements {
+		if j.Time.After(judged) {
+			judged = j.Time
+		}
+	}
+	return judged
+}
+
+func parseOutcome(s string) predictions.Outcome {
+	for _, o := range []predictions.Outcome{predictions.Right, predictions.Wrong} {
+		if o.String() == s {
+			return o
+		}
+	}
+	return predictions.Unknown
+}
+
+// DatasetAsOf returns the dataset as the site showed it at shown, as known by known, or
+// by every observation if zero. Versioned fields come from the history, and everything
+// else from the dataset, keeping only the responses and judgements made by then, and only
+// responses to predictions shown then.
+func DatasetAsOf(dataset *Dataset, h *History, shown, known time.Time) *Dataset {
+	current := make(map[int64]*predictions.PredictionSummary)
+	for _, p := range dataset.Predictions {
+		current[p.Id] = p
+	}
+
+	result := &Dataset{
+		Crawled: dataset.Crawled,
+		BaseUrl: dataset.BaseUrl,
+	}
+	for _, past := range h.AsOf(shown, known) {
+		if p := current[past.Id]; p != nil {
+			merged := *p
+			merged.Title = past.Title
+			merged.Deadline = past.Deadline
+			merged.Outcome = past.Outcome
+			merged.Judged = past.Judged
+			merged.MeanConfidence = past.MeanConfidence
+			merged.WagerCount = past.WagerCount
+			merged.Judgements = nil
+			for _, j := range p.Judgements {
+				if !j.Time.After(shown) {
+					merged.Judgements = append(merged.Judgements, j)
+				}
+			}
+			past = &merged
+		}
+		result.Predictions = append(result.Predictions, past)
+	}
+	shownIds := make(map[int64]bool)
+	for _, p := range result.Predictions {
+		shownIds[p.Id] = true
+	}
+	for _, r := range dataset.Responses {
+		if shownIds[r.Prediction] && !r.Time.After(shown) {
+			result.Responses = append(result.Responses, r)
+		}
+	}
+	return result
+}
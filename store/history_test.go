@@ -0,0 +1,171 @@
+package store
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatalf("Couldn't create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	s := NewStore(dir)
+
+	day := func(d int) time.Time {
+		return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	// Created on the 1st and wagered on the 2nd, crawled on the 3rd
+	err = s.Save(&Dataset{
+		Crawled: day(3),
+		Predictions: []*predictions.PredictionSummary{
+			{Id: 1, Title: "Original", Created: day(1), Deadline: day(20), MeanConfidence: 0.5, WagerCount: 1},
+		},
+		Responses: []*predictions.PredictionResponse{
+			{Prediction: 1, Time: day(2), Confidence: 0.5, Kind: predictions.WagerOnly},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error saving should have been nil, was %s", err)
+	}
+
+	// Retitled at some point before the crawl on the 10th, and judged on the 8th
+	err = s.Save(&Dataset{
+		Crawled: day(10),
+		Predictions: []*predictions.PredictionSummary{
+			{Id: 1, Title: "Edited", Created: day(1), Deadline: day(20), MeanConfidence: 0.5, WagerCount: 1, Outcome: predictions.Right, Judged: day(8)},
+		},
+		Responses: []*predictions.PredictionResponse{
+			{Prediction: 1, Time: day(2), Confidence: 0.5, Kind: predictions.WagerOnly},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error saving should have been nil, was %s", err)
+	}
+
+	history, err := s.LoadHistory()
+	if err != nil {
+		t.Fatalf("Error loading history should have been nil, was %s", err)
+	}
+	ph := history.Prediction(1)
+	if ph == nil || len(ph.Versions) != 7 {
+		t.Fatalf("Incorrect history; should have 7 versions, was %+v", ph)
+	}
+
+	tests := []struct {
+		shown, known   time.Time
+		exists         bool
+		title          string
+		outcome        predictions.Outcome
+		meanConfidence float64
+	}{
+		{day(1).Add(-time.Hour), time.Time{}, false, "", predictions.Unknown, 0},
+		{day(1), time.Time{}, true, "Original", predictions.Unknown, math.NaN()},
+		{day(2), time.Time{}, true, "Original", predictions.Unknown, 0.5},
+		{day(8), time.Time{}, true, "Original", predictions.Right, 0.5},
+		{day(10), time.Time{}, true, "Edited", predictions.Right, 0.5},
+		// As known after the first crawl, the later judgement and edit aren't visible
+		{day(10), day(3), true, "Original", predictions.Unknown, 0.5},
+	}
+	for _, test := range tests {
+		ps := history.AsOf(test.shown, test.known)
+		if !test.exists {
+			if len(ps) != 0 {
+				t.Errorf("Incorrect predictions at %s; should be none, was %d", test.shown, len(ps))
+			}
+			continue
+		}
+		if len(ps) != 1 {
+			t.Errorf("Incorrect predictions at %s known at %s; should be 1, was %d", test.shown, test.known, len(ps))
+			continue
+		}
+		p := ps[0]
+		confidenceCorrect := p.MeanConfidence == test.meanConfidence || (math.IsNaN(test.meanConfidence) && math.IsNaN(p.MeanConfidence))
+		if p.Title != test.title || p.Outcome != test.outcome || !confidenceCorrect || !p.Deadline.Equal(day(20)) {
+			t.Errorf("Incorrect prediction at %s known at %s; should be %s, %s with mean confidence %g, was %+v", test.shown, test.known, test.title, test.outcome, test.meanConfidence, p)
+		}
+	}
+}
+
+func TestHistoryRemoved(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time {
+		return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	kept := &predictions.PredictionSummary{Id: 1, Title: "Kept", Created: day(1), MeanConfidence: math.NaN()}
+	removed := &predictions.PredictionSummary{Id: 2, Title: "Removed", Created: day(1), MeanConfidence: math.NaN()}
+
+	history := NewHistory()
+	history.Record(&Dataset{Crawled: day(3), Predictions: []*predictions.PredictionSummary{kept, removed}})
+	history.Record(&Dataset{Crawled: day(5), Predictions: []*predictions.PredictionSummary{kept}})
+	history.Record(&Dataset{Crawled: day(7), Predictions: []*predictions.PredictionSummary{kept}})
+	history.Record(&Dataset{Crawled: day(9), Predictions: []*predictions.PredictionSummary{kept, removed}})
+
+	versions := 0
+	for _, v := range history.Prediction(2).Versions {
+		if v.Field == RemovedField {
+			versions++
+		}
+	}
+	if versions != 2 {
+		t.Errorf("Incorrect number of removal versions; should be %d, was %d", 2, versions)
+	}
+
+	tests := []struct {
+		shown time.Time
+		count int
+	}{
+		{day(4), 2},
+		{day(5), 1},
+		{day(8), 1},
+		{day(9), 2},
+	}
+	for _, test := range tests {
+		ps := history.AsOf(test.shown, time.Time{})
+		if len(ps) != test.count {
+			t.Errorf("Incorrect predictions at %s; should be %d, was %d", test.shown, test.count, len(ps))
+		}
+	}
+}
+
+func TestDatasetAsOfResponses(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time {
+		return time.Date(2020, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	dataset := &Dataset{
+		Crawled: day(9),
+		Predictions: []*predictions.PredictionSummary{
+			{Id: 1, Title: "Kept", Created: day(1), MeanConfidence: math.NaN()},
+			{Id: 2, Title: "Removed", Created: day(1), MeanConfidence: math.NaN()},
+			{Id: 3, Title: "Unrecorded", Created: day(1), MeanConfidence: math.NaN()},
+		},
+		Responses: []*predictions.PredictionResponse{
+			{Prediction: 1, Time: day(2), Kind: predictions.CommentOnly},
+			{Prediction: 2, Time: day(2), Kind: predictions.CommentOnly},
+			{Prediction: 3, Time: day(2), Kind: predictions.CommentOnly},
+		},
+	}
+
+	history := NewHistory()
+	history.Record(&Dataset{Crawled: day(3), Predictions: dataset.Predictions[:2]})
+	history.Record(&Dataset{Crawled: day(5), Predictions: dataset.Predictions[:1]})
+
+	past := DatasetAsOf(dataset, history, day(6), time.Time{})
+	if len(past.Predictions) != 1 || past.Predictions[0].Id != 1 {
+		t.Fatalf("Incorrect predictions as of day 6; should be only 1, was %+v", past.Predictions)
+	}
+	if len(past.Responses) != 1 || past.Responses[0].Prediction != 1 {
+		t.Errorf("Incorrect responses as of day 6; should be only the response to 1, was %+v", past.Responses)
+	}
+}
@@ -0,0 +1,155 @@
+package store
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"math"
+	"time"
+)
+
+// Records mirror the predictions types with JSON field names, representing NaN
+// confidences as null since JSON has no NaN. They are used both for stored datasets
+// and by the HTTP API.
+
+type PredictionRecord struct {
+	Id               int64               `json:"id"`
+	Title            string              `json:"title"`
+	Creator          string              `json:"creator"`
+	CreatorSlug      string              `json:"creator_slug"`
+	Created          time.Time           `json:"created"`
+	Deadline         time.Time           `json:"deadline"`
+	Judged           time.Time           `json:"judged"`
+	MeanConfidence   *float64            `json:"mean_confidence"`
+	WagerCount       int64               `json:"wager_count"`
+	Outcome          predictions.Outcome `json:"outcome"`
+	DetailsText      string              `json:"details_text"`
+	DetailsUrls      []string            `json:"details_urls"`
+	Judgements       []*JudgementRecord  `json:"judgements"`
+	Topics           []string            `json:"topics,omitempty"`
+	DuplicateCluster int64               `json:"duplicate_cluster,omitempty"`
+}
+
+type JudgementRecord struct {
+	Time     time.Time           `json:"time"`
+	User     string              `json:"user"`
+	UserSlug string              `json:"user_slug"`
+	Outcome  predictions.Outcome `json:"outcome"`
+}
+
+type ResponseRecord struct {
+	Prediction      int64                    `json:"prediction"`
+	Time            time.Time                `json:"time"`
+	User            string                   `json:"user"`
+	UserSlug        string                   `json:"user_slug"`
+	Confidence      *float64                 `json:"confidence"`
+	Kind            predictions.ResponseKind `json:"kind"`
+	Comment         string                   `json:"comment"`
+	CommentMarkdown string                   `json:"comment_markdown"`
+	Urls            []string                 `json:"urls"`
+	Mentions        []string                 `json:"mentions"`
+}
+
+func NewPredictionRecord(p *predictions.PredictionSummary) *PredictionRecord {
+	r := &PredictionRecord{
+		Id:               p.Id,
+		Title:            p.Title,
+		Creator:          p.Creator,
+		CreatorSlug:      p.CreatorSlug,
+		Created:          p.Created,
+		Deadline:         p.Deadline,
+		Judged:           p.Judged,
+		MeanConfidence:   NullableFloat(p.MeanConfidence),
+		WagerCount:       p.WagerCount,
+		Outcome:          p.Outcome,
+		DetailsText:      p.Details.Text,
+		DetailsUrls:      p.Details.Urls,
+		Topics:           p.Topics,
+		DuplicateCluster: p.DuplicateCluster,
+	}
+	for _, j := range p.Judgements {
+		r.Judgements = append(r.Judgements, &JudgementRecord{
+			Time:     j.Time,
+			User:     j.User,
+			UserSlug: j.UserSlug,
+			Outcome:  j.Outcome,
+		})
+	}
+
+	return r
+}
+
+func (r *PredictionRecord) ToSummary() *predictions.PredictionSummary {
+	p := &predictions.PredictionSummary{
+		Id:             r.Id,
+		Title:          r.Title,
+		Creator:        r.Creator,
+		CreatorSlug:    r.CreatorSlug,
+		Created:        r.Created,
+		Deadline:       r.Deadline,
+		Judged:         r.Judged,
+		MeanConfidence: floatOrNaN(r.MeanConfidence),
+		WagerCount:     r.WagerCount,
+		Outcome:        r.Outcome,
+		Details: predictions.PredictionDetails{
+			Text: r.DetailsText,
+			Urls: r.DetailsUrls,
+		},
+		Topics:           r.Topics,
+		DuplicateCluster: r.DuplicateCluster,
+	}
+	for _, j := range r.Judgements {
+		p.Judgements = append(p.Judgements, &predictions.PredictionJudgement{
+			Prediction: r.Id,
+			Time:       j.Time,
+			User:       j.User,
+			UserSlug:   j.UserSlug,
+			Outcome:    j.Outcome,
+		})
+	}
+
+	return p
+}
+
+func NewResponseRecord(r *predictions.PredictionResponse) *ResponseRecord {
+	return &ResponseRecord{
+		Prediction:      r.Prediction,
+		Time:            r.Time,
+		User:            r.User,
+		UserSlug:        r.UserSlug,
+		Confidence:      NullableFloat(r.Confidence),
+		Kind:            r.Kind,
+		Comment:         r.Comment,
+		CommentMarkdown: r.CommentMarkdown,
+		Urls:            r.Urls,
+		Mentions:        r.Mentions,
+	}
+}
+
+func (r *ResponseRecord) ToResponse() *predictions.PredictionResponse {
+	return &predictions.PredictionResponse{
+		Prediction:      r.Prediction,
+		Time:            r.Time,
+		User:            r.User,
+		UserSlug:        r.UserSlug,
+		Confidence:      floatOrNaN(r.Confidence),
+		Kind:            r.Kind,
+		Comment:         r.Comment,
+		CommentMarkdown: r.CommentMarkdown,
+		Urls:            r.Urls,
+		Mentions:        r.Mentions,
+	}
+}
+
+// NullableFloat returns nil for NaN, so it can be represented in JSON as null.
+func NullableFloat(f float64) *float64 {
+	if math.IsNaN(f) {
+		return nil
+	}
+	return &f
+}
+
+func floatOrNaN(f *float64) float64 {
+	if f == nil {
+		return math.NaN()
+	}
+	return *f
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"encoding/json"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"strings"
+	"testing"
+)
+
+func TestRecordsEncodeEnumsByName(t *testing.T) {
+	t.Parallel()
+
+	p, err := json.Marshal(&PredictionRecord{Outcome: predictions.Wrong})
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+	if !strings.Contains(string(p), `"outcome":"wrong"`) {
+		t.Errorf("Outcome should be encoded by name, was %s", p)
+	}
+
+	r, err := json.Marshal(&ResponseRecord{Kind: predictions.WagerAndComment})
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+	if !strings.Contains(string(r), `"kind":"wager_and_comment"`) {
+		t.Errorf("Kind should be encoded by name, was %s", r)
+	}
+
+	var decoded ResponseRecord
+	err = json.Unmarshal(r, &decoded)
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+	if decoded.Kind != predictions.WagerAndComment {
+		t.Errorf("Incorrect kind; should be %s, was %s", predictions.WagerAndComment, decoded.Kind)
+	}
+}
+
+func TestRecordsDecodeLegacyCodes(t *testing.T) {
+	t.Parallel()
+
+	var p PredictionRecord
+	err := json.Unmarshal([]byte(`{"outcome":1}`), &p)
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+	if p.Outcome != predictions.Right {
+		t.Errorf("Incorrect outcome; should be %s, was %s", predictions.Right, p.Outcome)
+	}
+
+	var r ResponseRecord
+	err = json.Unmarshal([]byte(`{"kind":1}`), &r)
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+	if r.Kind != predictions.CommentOnly {
+		t.Errorf("Incorrect kind; should be %s, was %s", predictions.CommentOnly, r.Kind)
+	}
+
+	err = json.Unmarshal([]byte(`{"kind":"guess"}`), &r)
+	if err == nil {
+		t.Errorf("Expected error decoding an invalid kind")
+	}
+}
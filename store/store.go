@@ -0,0 +1,140 @@
+package store
+
+import (
+	"encoding/json"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Dataset is the result of one crawl of a PredictionBook instance.
+type Dataset struct {
+	Crawled     time.Time
+	BaseUrl     string
+	Predictions []*predictions.PredictionSummary
+	Responses   []*predictions.PredictionResponse
+}
+
+// Store keeps the most recent dataset as JSON in a local directory, along with the
+// history of values seen by every dataset saved there.
+type Store struct {
+	dir string
+}
+
+type datasetFile struct {
+	Crawled     time.Time           `json:"crawled"`
+	BaseUrl     string              `json:"base_url"`
+	Predictions []*PredictionRecord `json:"predictions"`
+	Responses   []*ResponseRecord   `json:"responses"`
+}
+
+const datasetFileName = "dataset.json"
+
+func NewStore(dir string) *Store {
+	return &Store{
+		dir: dir,
+	}
+}
+
+// Save replaces the stored dataset, and records any changed values in the stored history.
+// Both files are written in full before either is moved into place, so readers never see
+// a partially written dataset, and the history is moved first so it is never behind the
+// dataset; recording the same dataset twice changes nothing.
+func (s *Store) Save(dataset *Dataset) error {
+	err := os.MkdirAll(s.dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	history, err := s.LoadHistory()
+	if err != nil {
+		return err
+	}
+	history.Record(dataset)
+
+	f := &datasetFile{
+		Crawled: dataset.Crawled,
+		BaseUrl: dataset.BaseUrl,
+	}
+	for _, p := range dataset.Predictions {
+		f.Predictions = append(f.Predictions, NewPredictionRecord(p))
+	}
+	for _, r := range dataset.Responses {
+		f.Responses = append(f.Responses, NewResponseRecord(r))
+	}
+
+	historyTmp, err := s.writeTemp(historyFileName, history)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(historyTmp)
+
+	datasetTmp, err := s.writeTemp(datasetFileName, f)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(datasetTmp)
+
+	err = os.Rename(historyTmp, filepath.Join(s.dir, historyFileName))
+	if err != nil {
+		return err
+	}
+	return os.Rename(datasetTmp, filepath.Join(s.dir, datasetFileName))
+}
+
+// writeTemp writes v as JSON to a new temporary file in the store, named after the file
+// it will replace, and returns its path.
+func (s *Store) writeTemp(name string, v interface{}) (string, error) {
+	tmp, err := ioutil.TempFile(s.dir, name+".tmp")
+	if err != nil {
+		return "", err
+	}
+
+	err = json.NewEncoder(tmp).Encode(v)
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+func (s *Store) Load() (*Dataset, error) {
+	file, err := os.Open(filepath.Join(s.dir, datasetFileName))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return readDatasetJson(file)
+}
+
+func readDatasetJson(r io.Reader) (*Dataset, error) {
+	var f datasetFile
+	err := json.NewDecoder(r).Decode(&f)
+	if err != nil {
+		return nil, err
+	}
+
+	dataset := &Dataset{
+		Crawled: f.Crawled,
+		BaseUrl: f.BaseUrl,
+	}
+	for _, p := range f.Predictions {
+		dataset.Predictions = append(dataset.Predictions, p.ToSummary())
+	}
+	for _, r := range f.Responses {
+		dataset.Responses = append(dataset.Responses, r.ToResponse())
+	}
+
+	return dataset, nil
+}
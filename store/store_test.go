@@ -0,0 +1,120 @@
+package store
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatalf("Couldn't create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := NewStore(dir)
+	err = s.Save(&Dataset{
+		Crawled: time.Unix(1000, 0),
+		BaseUrl: "https://example.org",
+		Predictions: []*predictions.PredictionSummary{
+			{
+				Id:             1,
+				Title:          "Unwagered",
+				MeanConfidence: math.NaN(),
+				Outcome:        predictions.Right,
+				Judgements: []*predictions.PredictionJudgement{
+					{Prediction: 1, UserSlug: "Cato", Outcome: predictions.Right},
+				},
+				Topics: []string{"politics"},
+			},
+		},
+		Responses: []*predictions.PredictionResponse{
+			{Prediction: 1, User: "jbeshir", Confidence: math.NaN(), Kind: predictions.CommentOnly, Comment: "Hm"},
+			{Prediction: 1, User: "jbeshir", Confidence: 0.25, Kind: predictions.WagerOnly},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error saving should have been nil, was %s", err)
+	}
+
+	dataset, err := s.Load()
+	if err != nil {
+		t.Fatalf("Error loading should have been nil, was %s", err)
+	}
+	if dataset.BaseUrl != "https://example.org" || dataset.Crawled.Unix() != 1000 {
+		t.Errorf("Incorrect dataset metadata, was %s crawled at %d", dataset.BaseUrl, dataset.Crawled.Unix())
+	}
+	if len(dataset.Predictions) != 1 || !math.IsNaN(dataset.Predictions[0].MeanConfidence) {
+		t.Fatalf("Incorrect predictions loaded; should be one with NaN mean confidence, was %+v", dataset.Predictions)
+	}
+	if len(dataset.Predictions[0].Judgements) != 1 || dataset.Predictions[0].Judgements[0].Prediction != 1 {
+		t.Errorf("Incorrect judgements loaded, was %+v", dataset.Predictions[0].Judgements)
+	}
+	if len(dataset.Predictions[0].Topics) != 1 || dataset.Predictions[0].Topics[0] != "politics" {
+		t.Errorf("Incorrect topics loaded, was %v", dataset.Predictions[0].Topics)
+	}
+	if len(dataset.Responses) != 2 {
+		t.Fatalf("Incorrect number of responses loaded; should be %d, was %d", 2, len(dataset.Responses))
+	}
+	if !math.IsNaN(dataset.Responses[0].Confidence) || dataset.Responses[1].Confidence != 0.25 {
+		t.Errorf("Incorrect response confidences loaded; should be NaN and 0.25, were %g and %g", dataset.Responses[0].Confidence, dataset.Responses[1].Confidence)
+	}
+	if dataset.Responses[0].Kind != predictions.CommentOnly {
+		t.Errorf("Incorrect response kind loaded; should be %s, was %s", predictions.CommentOnly, dataset.Responses[0].Kind)
+	}
+}
+
+func TestStoreLoadMissing(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewStore("testdata/does-not-exist").Load()
+	if err == nil {
+		t.Errorf("Error should have been returned loading a missing dataset")
+	}
+}
+
+func TestStoreSaveFailureKeepsDataset(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatalf("Couldn't create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := NewStore(dir)
+	err = s.Save(&Dataset{Crawled: time.Unix(1000, 0)})
+	if err != nil {
+		t.Fatalf("Error saving should have been nil, was %s", err)
+	}
+
+	// A history which can't be read or replaced must stop the dataset being replaced too
+	historyPath := filepath.Join(dir, historyFileName)
+	err = os.Remove(historyPath)
+	if err != nil {
+		t.Fatalf("Couldn't remove history: %s", err)
+	}
+	err = os.Mkdir(historyPath, 0755)
+	if err != nil {
+		t.Fatalf("Couldn't create directory in place of history: %s", err)
+	}
+	err = s.Save(&Dataset{Crawled: time.Unix(2000, 0)})
+	if err == nil {
+		t.Errorf("Error saving should have been returned")
+	}
+
+	dataset, err := s.Load()
+	if err != nil {
+		t.Fatalf("Error loading should have been nil, was %s", err)
+	}
+	if dataset.Crawled.Unix() != 1000 {
+		t.Errorf("Incorrect dataset after failed save; should be crawled at %d, was %d", 1000, dataset.Crawled.Unix())
+	}
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"fmt"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"math"
+)
+
+// Problem is an inconsistency found in a dataset. Prediction is zero for problems
+// not specific to one prediction.
+type Problem struct {
+	Prediction int64
+	Message    string
+}
+
+func (p *Problem) String() string {
+	if p.Prediction == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("prediction %d: %s", p.Prediction, p.Message)
+}
+
+// confidenceTolerance allows for mean confidences rounded to whole percentages by the site.
+const confidenceTolerance = 0.005
+
+// Verify checks a dataset is internally consistent: prediction ids are unique, every response
+// belongs to a prediction, wager counts and mean confidences agree with the wagers stored,
+// confidences are probabilities, and outcomes agree with the most recent judgement.
+func Verify(dataset *Dataset) (problems []*Problem) {
+	byId := make(map[int64]*predictions.PredictionSummary)
+	for _, p := range dataset.Predictions {
+		if _, exists := byId[p.Id]; exists {
+			problems = append(problems, &Problem{p.Id, "appears more than once"})
+		}
+		byId[p.Id] = p
+	}
+
+	wagerCounts := make(map[int64]int64)
+	confidenceSums := make(map[int64]float64)
+	for _, r := range dataset.Responses {
+		if _, exists := byId[r.Prediction]; !exists {
+			problems = append(problems, &Problem{r.Prediction, fmt.Sprintf("response by %s belongs to no stored prediction", r.User)})
+			continue
+		}
+		if !r.Kind.IsWager() {
+			continue
+		}
+		if r.Confidence < 0 || r.Confidence > 1 {
+			problems = append(problems, &Problem{r.Prediction, fmt.Sprintf("wager by %s has confidence %g outside 0 to 1", r.User, r.Confidence)})
+		}
+		wagerCounts[r.Prediction]++
+		confidenceSums[r.Prediction] += r.Confidence
+	}
+
+	for _, p := range dataset.Predictions {
+		if p.WagerCount != wagerCounts[p.Id] {
+			problems = append(problems, &Problem{p.Id, fmt.Sprintf("wager count is %d, but %d wagers are stored", p.WagerCount, wagerCounts[p.Id])})
+		} else if p.WagerCount > 0 {
+			mean := confidenceSums[p.Id] / float64(p.WagerCount)
+			if math.IsNaN(p.MeanConfidence) || math.Abs(p.MeanConfidence-mean) > confidenceTolerance {
+				problems = append(problems, &Problem{p.Id, fmt.Sprintf("mean confidence is %g, but stored wagers average %g", p.MeanConfidence, mean)})
+			}
+		}
+
+		if len(p.Judgements) > 0 {
+			latest := p.Judgements[len(p.Judgements)-1]
+			if latest.Outcome != p.Outcome {
+				problems = append(problems, &Problem{p.Id, fmt.Sprintf("outcome is %s, but was last judged %s", p.Outcome, latest.Outcome)})
+			}
+		}
+	}
+
+	return
+}
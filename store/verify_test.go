@@ -0,0 +1,52 @@
+package store
+
+import (
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"math"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	dataset := &Dataset{
+		Predictions: []*predictions.PredictionSummary{
+			{Id: 1, WagerCount: 2, MeanConfidence: 0.6, Outcome: predictions.Right, Judgements: []*predictions.PredictionJudgement{
+				{Prediction: 1, Outcome: predictions.Wrong},
+				{Prediction: 1, Outcome: predictions.Right},
+			}},
+			{Id: 2, WagerCount: 2, MeanConfidence: 0.5},
+			{Id: 3, WagerCount: 1, MeanConfidence: 0.9, Outcome: predictions.Right, Judgements: []*predictions.PredictionJudgement{
+				{Prediction: 3, Outcome: predictions.Wrong},
+			}},
+			{Id: 3, MeanConfidence: math.NaN()},
+		},
+		Responses: []*predictions.PredictionResponse{
+			{Prediction: 1, Confidence: 0.5, Kind: predictions.WagerOnly},
+			{Prediction: 1, Confidence: 0.7, Kind: predictions.WagerAndComment},
+			{Prediction: 1, Confidence: math.NaN(), Kind: predictions.CommentOnly},
+			{Prediction: 2, Confidence: 0.5, Kind: predictions.WagerOnly},
+			{Prediction: 3, Confidence: 1.5, Kind: predictions.WagerOnly},
+			{Prediction: 4, Confidence: 0.5, Kind: predictions.WagerOnly},
+		},
+	}
+
+	expected := []string{
+		"prediction 3: appears more than once",
+		"prediction 3: wager by  has confidence 1.5 outside 0 to 1",
+		"prediction 4: response by  belongs to no stored prediction",
+		"prediction 2: wager count is 2, but 1 wagers are stored",
+		"prediction 3: mean confidence is 0.9, but stored wagers average 1.5",
+		"prediction 3: outcome is right, but was last judged wrong",
+		"prediction 3: wager count is 0, but 1 wagers are stored",
+	}
+	problems := Verify(dataset)
+	if len(problems) != len(expected) {
+		t.Fatalf("Incorrect number of problems; should be %d, was %d: %v", len(expected), len(problems), problems)
+	}
+	for i, p := range problems {
+		if p.String() != expected[i] {
+			t.Errorf("Incorrect problem %d; should be %q, was %q", i, expected[i], p.String())
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package watch
+
+import (
+	"errors"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"github.com/jbeshir/predictionbook-extractor/store"
+	"time"
+)
+
+type EventKind int64
+
+const (
+	PredictionCreated EventKind = iota
+	PredictionJudged
+	OutcomeChanged
+	NewEstimate
+	DeadlinePassed
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case PredictionCreated:
+		return "prediction_created"
+	case PredictionJudged:
+		return "prediction_judged"
+	case OutcomeChanged:
+		return "outcome_changed"
+	case NewEstimate:
+		return "new_estimate"
+	case DeadlinePassed:
+		return "deadline_passed"
+	default:
+		return "unknown"
+	}
+}
+
+func (k EventKind) MarshalText() ([]byte, error) {
+	s := k.String()
+	if s == "unknown" {
+		return nil, errors.New("unknown event kind")
+	}
+	return []byte(s), nil
+}
+
+func (k *EventKind) UnmarshalText(text []byte) error {
+	for candidate := PredictionCreated; candidate <= DeadlinePassed; candidate++ {
+		if candidate.String() == string(text) {
+			*k = candidate
+			return nil
+		}
+	}
+	return errors.New("unknown event kind: " + string(text))
+}
+
+// Event describes a change observed between two extractions. NewEstimate events also
+// carry the new Response.
+type Event struct {
+	Kind            EventKind
+	Observed        time.Time
+	Prediction      *predictions.PredictionSummary
+	PreviousOutcome predictions.Outcome
+	Response        *predictions.PredictionResponse
+}
+
+// EventRecord is an event as written by sinks, with the prediction and response as
+// stored records, so field names match the store and API and NaN confidences are null.
+type EventRecord struct {
+	Kind            EventKind               `json:"kind"`
+	Observed        time.Time               `json:"observed"`
+	Prediction      *store.PredictionRecord `json:"prediction"`
+	PreviousOutcome predictions.Outcome     `json:"previous_outcome"`
+	Response        *store.ResponseRecord   `json:"response,omitempty"`
+}
+
+func NewEventRecord(e *Event) *EventRecord {
+	r := &EventRecord{
+		Kind:            e.Kind,
+		Observed:        e.Observed,
+		PreviousOutcome: e.PreviousOutcome,
+	}
+	if e.Prediction != nil {
+		r.Prediction = store.NewPredictionRecord(e.Prediction)
+	}
+	if e.Response != nil {
+		r.Response = store.NewResponseRecord(e.Response)
+	}
+	return r
+}
+
+// DiffSummaries compares two successive extractions of the same predictions, returning
+// events for predictions which are new in current, or whose outcome changed.
+// Predictions missing from current are ignored, as they may simply have moved to a later page.
+func DiffSummaries(previous, current []*predictions.PredictionSummary, observed time.Time) (events []*Event) {
+	previousById := make(map[int64]*predictions.PredictionSummary)
+	for _, p := range previous {
+		previousById[p.Id] = p
+	}
+
+	for _, p := range current {
+		old, exists := previousById[p.Id]
+		if !exists {
+			events = append(events, &Event{
+				Kind:       PredictionCreated,
+				Observed:   observed,
+				Prediction: p,
+			})
+			continue
+		}
+
+		if old.Outcome == p.Outcome {
+			continue
+		}
+
+		kind := OutcomeChanged
+		if old.Outcome == predictions.Unknown {
+			kind = PredictionJudged
+		}
+		events = append(events, &Event{
+			Kind:            kind,
+			Observed:        observed,
+			Prediction:      p,
+			PreviousOutcome: old.Outcome,
+		})
+	}
+
+	return
+}
+
+// PassedDeadlines returns events for the unjudged predictions whose deadline passed after
+// since and by observed.
+func PassedDeadlines(ps []*predictions.PredictionSummary, since, observed time.Time) (events []*Event) {
+	for _, p := range ps {
+		if p.Outcome == predictions.Unknown && p.Deadline.After(since) && !p.Deadline.After(observed) {
+			events = append(events, &Event{
+				Kind:       DeadlinePassed,
+				Observed:   observed,
+				Prediction: p,
+			})
+		}
+	}
+
+	return
+}
+
+// DiffResponses compares two successive extractions of a prediction's responses, returning
+// events for wagers which are present in current but not previous. Comment-only responses are ignored.
+func DiffResponses(prediction *predictions.PredictionSummary, previous, current []*predictions.PredictionResponse, observed time.Time) (events []*Event) {
+	seen := make(map[responseKey]bool)
+	for _, r := range previous {
+		seen[keyForResponse(r)] = true
+	}
+
+	for _, r := range current {
+		if !r.Kind.IsWager() || seen[keyForResponse(r)] {
+			continue
+		}
+
+		events = append(events, &Event{
+			Kind:       NewEstimate,
+			Observed:   observed,
+			Prediction: prediction,
+			Response:   r,
+		})
+	}
+
+	return
+}
+
+type responseKey struct {
+	prediction int64
+	user       string
+	time       int64
+	confidence float64
+}
+
+func keyForResponse(r *predictions.PredictionResponse) responseKey {
+	return responseKey{
+		prediction: r.Prediction,
+		user:       r.User,
+		time:       r.Time.Unix(),
+		confidence: r.Confidence,
+	}
+}
@@ -0,0 +1,46 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Sink receives events detected by a Watcher.
+type Sink interface {
+	Emit(ctx context.Context, event *Event) error
+}
+
+// JsonLinesSink writes each event's record as a single line of JSON.
+type JsonLinesSink struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+func NewJsonLinesSink(w io.Writer) *JsonLinesSink {
+	return &JsonLinesSink{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+func (s *JsonLinesSink) Emit(ctx context.Context, event *Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.encoder.Encode(NewEventRecord(event))
+}
+
+// MultiSink emits each event to every sink in turn, stopping at the first error.
+type MultiSink []Sink
+
+func (m MultiSink) Emit(ctx context.Context, event *Event) error {
+	for _, s := range m {
+		err := s.Emit(ctx, event)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
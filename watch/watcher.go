@@ -0,0 +1,185 @@
+package watch
+
+import (
+	"context"
+	"github.com/jbeshir/predictionbook-extractor/logging"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"math"
+	"sort"
+	"time"
+)
+
+type Source interface {
+	RetrievePredictionListPage(ctx context.Context, index int64) (predictions []*predictions.PredictionSummary, pageInfo *predictions.PredictionListPageInfo, err error)
+	RetrievePredictionResponses(ctx context.Context, prediction int64) (summary *predictions.PredictionSummary, responses []*predictions.PredictionResponse, err error)
+	RetrieveUpcomingPage(ctx context.Context, index int64) (predictions []*predictions.PredictionSummary, pageInfo *predictions.PredictionListPageInfo, err error)
+}
+
+// Watcher polls the first page of the prediction list, emitting events to its sink for
+// new predictions, new estimates and judgements of predictions still on that page.
+// Responses are only retrieved for predictions whose wager count or mean confidence changed.
+//
+// It also polls the first page of upcoming predictions, which lists the soonest deadlines
+// first, remembering unjudged predictions from either page until their deadline passes.
+type Watcher struct {
+	source   Source
+	interval time.Duration
+	sink     Sink
+	logger   logging.Logger
+	now      func() time.Time
+
+	previous   []*predictions.PredictionSummary
+	responses  map[int64][]*predictions.PredictionResponse
+	pending    map[int64]*predictions.PredictionSummary
+	lastPolled time.Time
+}
+
+// NewWatcher returns a watcher polling at the given interval. Errors polling are logged
+// as warnings to the logger, which may be nil.
+func NewWatcher(source Source, interval time.Duration, sink Sink, logger logging.Logger) *Watcher {
+	return &Watcher{
+		source:    source,
+		interval:  interval,
+		sink:      sink,
+		logger:    logging.OrDiscard(logger),
+		now:       time.Now,
+		responses: make(map[int64][]*predictions.PredictionResponse),
+		pending:   make(map[int64]*predictions.PredictionSummary),
+	}
+}
+
+// Run polls until the context is cancelled. Errors retrieving pages are logged and
+// retried at the next interval; errors from the sink stop the watcher.
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		err := w.Poll(ctx)
+		if err != nil {
+			if _, ok := err.(*sinkError); ok {
+				return err
+			}
+			w.logger.Warn("Error polling for changes", logging.ErrorKey, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll retrieves the first list and upcoming pages once and emits events for changes since
+// the last poll. The first poll only records the current state.
+func (w *Watcher) Poll(ctx context.Context) error {
+	current, _, err := w.source.RetrievePredictionListPage(ctx, 1)
+	if err != nil {
+		return err
+	}
+	upcoming, _, err := w.source.RetrieveUpcomingPage(ctx, 1)
+	if err != nil {
+		return err
+	}
+
+	observed := w.now()
+	if w.lastPolled.IsZero() {
+		w.previous = current
+		w.trackDeadlines(upcoming, current)
+		w.lastPolled = observed
+		return nil
+	}
+
+	events := DiffSummaries(w.previous, current, observed)
+	events = append(events, PassedDeadlines(w.trackDeadlines(upcoming, current), w.lastPolled, observed)...)
+
+	previousById := make(map[int64]*predictions.PredictionSummary)
+	for _, p := range w.previous {
+		previousById[p.Id] = p
+	}
+	responses := make(map[int64][]*predictions.PredictionResponse)
+	for _, p := range current {
+		old, exists := previousById[p.Id]
+		if exists && old.WagerCount == p.WagerCount && sameConfidence(old.MeanConfidence, p.MeanConfidence) {
+			if rs, retrieved := w.responses[p.Id]; retrieved {
+				responses[p.Id] = rs
+			}
+			continue
+		}
+
+		_, rs, err := w.source.RetrievePredictionResponses(ctx, p.Id)
+		if err != nil {
+			return err
+		}
+
+		// Without an earlier retrieval, only responses made since the last poll are new
+		previousResponses, retrieved := w.responses[p.Id]
+		if !retrieved {
+			for _, r := range rs {
+				if exists && !r.Time.After(w.lastPolled) {
+					previousResponses = append(previousResponses, r)
+				}
+			}
+		}
+
+		events = append(events, DiffResponses(p, previousResponses, rs, observed)...)
+		responses[p.Id] = rs
+	}
+
+	for _, event := range events {
+		err := w.sink.Emit(ctx, event)
+		if err != nil {
+			return &sinkError{err}
+		}
+	}
+
+	w.previous = current
+	w.responses = responses
+	w.lastPolled = observed
+	for id, p := range w.pending {
+		if !p.Deadline.After(observed) {
+			delete(w.pending, id)
+		}
+	}
+	return nil
+}
+
+// trackDeadlines updates the remembered unjudged predictions from the latest pages, and
+// returns them, soonest deadline first.
+func (w *Watcher) trackDeadlines(pages ...[]*predictions.PredictionSummary) []*predictions.PredictionSummary {
+	for _, page := range pages {
+		for _, p := range page {
+			if p.Outcome != predictions.Unknown || p.Deadline.IsZero() {
+				delete(w.pending, p.Id)
+				continue
+			}
+			w.pending[p.Id] = p
+		}
+	}
+
+	var pending []*predictions.PredictionSummary
+	for _, p := range w.pending {
+		pending = append(pending, p)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].Deadline.Equal(pending[j].Deadline) {
+			return pending[i].Deadline.Before(pending[j].Deadline)
+		}
+		return pending[i].Id < pending[j].Id
+	})
+	return pending
+}
+
+type sinkError struct {
+	err error
+}
+
+func (e *sinkError) Error() string {
+	return "error emitting event: " + e.err.Error()
+}
+
+// sameConfidence reports whether two mean confidences are equal, treating unknown ones as equal.
+func sameConfidence(a, b float64) bool {
+	return a == b || (math.IsNaN(a) && math.IsNaN(b))
+}
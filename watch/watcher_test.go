@@ -0,0 +1,249 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"math"
+	"testing"
+	"time"
+)
+
+type TestSource struct {
+	Pages     [][]*predictions.PredictionSummary
+	Upcoming  [][]*predictions.PredictionSummary
+	Responses map[int64][]*predictions.PredictionResponse
+	calls     int
+}
+
+func (s *TestSource) RetrievePredictionResponses(ctx context.Context, prediction int64) (*predictions.PredictionSummary, []*predictions.PredictionResponse, error) {
+	return &predictions.PredictionSummary{Id: prediction}, s.Responses[prediction], nil
+}
+
+func (s *TestSource) RetrievePredictionListPage(ctx context.Context, index int64) ([]*predictions.PredictionSummary, *predictions.PredictionListPageInfo, error) {
+	if s.calls >= len(s.Pages) {
+		return nil, nil, errors.New("no more pages")
+	}
+
+	page := s.Pages[s.calls]
+	s.calls++
+	return page, &predictions.PredictionListPageInfo{Index: index, LastPage: 1}, nil
+}
+
+// RetrieveUpcomingPage returns the upcoming page for the poll in progress, if there is one.
+func (s *TestSource) RetrieveUpcomingPage(ctx context.Context, index int64) ([]*predictions.PredictionSummary, *predictions.PredictionListPageInfo, error) {
+	var page []*predictions.PredictionSummary
+	if s.calls <= len(s.Upcoming) {
+		page = s.Upcoming[s.calls-1]
+	}
+	return page, &predictions.PredictionListPageInfo{Index: index, LastPage: 1}, nil
+}
+
+type TestSink struct {
+	Events []*Event
+}
+
+func (s *TestSink) Emit(ctx context.Context, event *Event) error {
+	s.Events = append(s.Events, event)
+	return nil
+}
+
+func TestWatcherPoll(t *testing.T) {
+	t.Parallel()
+
+	recent := time.Now().Add(time.Hour)
+	source := &TestSource{
+		Pages: [][]*predictions.PredictionSummary{
+			{
+				{Id: 2, Outcome: predictions.Unknown, WagerCount: 1},
+				{Id: 1, Outcome: predictions.Right, WagerCount: 1},
+			},
+			{
+				{Id: 3, Outcome: predictions.Unknown, WagerCount: 1},
+				{Id: 2, Outcome: predictions.Wrong, WagerCount: 2},
+				{Id: 1, Outcome: predictions.Wrong, WagerCount: 1},
+			},
+		},
+		Responses: map[int64][]*predictions.PredictionResponse{
+			3: {
+				{Prediction: 3, User: "a", Time: recent, Confidence: 0.5, Kind: predictions.WagerOnly},
+			},
+			2: {
+				{Prediction: 2, User: "a", Time: time.Unix(1, 0), Confidence: 0.5, Kind: predictions.WagerOnly},
+				{Prediction: 2, User: "b", Time: recent, Confidence: math.NaN(), Kind: predictions.CommentOnly},
+				{Prediction: 2, User: "c", Time: recent, Confidence: 0.2, Kind: predictions.WagerAndComment},
+			},
+		},
+	}
+	sink := &TestSink{}
+	w := NewWatcher(source, 0, sink, nil)
+
+	err := w.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+	if len(sink.Events) != 0 {
+		t.Fatalf("First poll should emit no events, emitted %d", len(sink.Events))
+	}
+
+	err = w.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+	if len(sink.Events) != 5 {
+		t.Fatalf("Incorrect number of events; should be %d, was %d", 5, len(sink.Events))
+	}
+
+	expected := []struct {
+		kind EventKind
+		id   int64
+	}{
+		{PredictionCreated, 3},
+		{PredictionJudged, 2},
+		{OutcomeChanged, 1},
+		{NewEstimate, 3},
+		{NewEstimate, 2},
+	}
+	for i, e := range expected {
+		if sink.Events[i].Kind != e.kind || sink.Events[i].Prediction.Id != e.id {
+			t.Errorf("Incorrect event %d; should be %s for %d, was %s for %d", i, e.kind, e.id, sink.Events[i].Kind, sink.Events[i].Prediction.Id)
+		}
+	}
+	if sink.Events[2].PreviousOutcome != predictions.Right {
+		t.Errorf("Incorrect previous outcome; should be %d, was %d", predictions.Right, sink.Events[2].PreviousOutcome)
+	}
+	if sink.Events[4].Response.User != "c" {
+		t.Errorf("Incorrect new estimate user; should be %s, was %s", "c", sink.Events[4].Response.User)
+	}
+
+	err = w.Poll(context.Background())
+	if err == nil {
+		t.Errorf("Error from source should have been returned")
+	}
+}
+
+func TestJsonLinesSink(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	sink := NewJsonLinesSink(&buf)
+	err := sink.Emit(context.Background(), &Event{
+		Kind:       PredictionJudged,
+		Prediction: &predictions.PredictionSummary{Id: 7},
+	})
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+
+	var decoded map[string]interface{}
+	err = json.Unmarshal(buf.Bytes(), &decoded)
+	if err != nil {
+		t.Fatalf("Couldn't decode emitted line: %s", err)
+	}
+	if decoded["kind"] != "prediction_judged" {
+		t.Errorf("Incorrect kind; should be %s, was %v", "prediction_judged", decoded["kind"])
+	}
+	if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+		t.Errorf("Event should have been written as a single line, was %q", buf.String())
+	}
+}
+
+func TestJsonLinesSinkNaN(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	sink := NewJsonLinesSink(&buf)
+	err := sink.Emit(context.Background(), &Event{
+		Kind:       NewEstimate,
+		Prediction: &predictions.PredictionSummary{Id: 7, MeanConfidence: math.NaN()},
+		Response:   &predictions.PredictionResponse{Prediction: 7, Confidence: math.NaN(), Kind: predictions.CommentOnly},
+	})
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+
+	var decoded struct {
+		Prediction map[string]interface{} `json:"prediction"`
+		Response   map[string]interface{} `json:"response"`
+	}
+	err = json.Unmarshal(buf.Bytes(), &decoded)
+	if err != nil {
+		t.Fatalf("Couldn't decode emitted line: %s", err)
+	}
+	if decoded.Prediction["id"] != float64(7) || decoded.Prediction["mean_confidence"] != nil {
+		t.Errorf("Incorrect prediction record, was %v", decoded.Prediction)
+	}
+	if decoded.Response["confidence"] != nil || decoded.Response["kind"] != "comment" {
+		t.Errorf("Incorrect response record, was %v", decoded.Response)
+	}
+}
+
+func TestPassedDeadlines(t *testing.T) {
+	t.Parallel()
+
+	since := time.Unix(1000, 0)
+	observed := time.Unix(2000, 0)
+	previous := []*predictions.PredictionSummary{
+		{Id: 1, Deadline: time.Unix(1500, 0)},
+		{Id: 2, Deadline: time.Unix(500, 0)},
+		{Id: 3, Deadline: time.Unix(2500, 0)},
+		{Id: 4, Deadline: time.Unix(1500, 0), Outcome: predictions.Right},
+	}
+
+	events := PassedDeadlines(previous, since, observed)
+	if len(events) != 1 {
+		t.Fatalf("Incorrect number of events; should be %d, was %d", 1, len(events))
+	}
+	if events[0].Kind != DeadlinePassed || events[0].Prediction.Id != 1 {
+		t.Errorf("Incorrect event; should be %s for %d, was %s for %d", DeadlinePassed, 1, events[0].Kind, events[0].Prediction.Id)
+	}
+}
+
+func TestWatcherDeadlinePassed(t *testing.T) {
+	t.Parallel()
+
+	// Prediction 1 is only on the upcoming page, and falls off it once its deadline passes;
+	// prediction 2 is judged before its deadline, and prediction 3's deadline is later.
+	source := &TestSource{
+		Pages: [][]*predictions.PredictionSummary{
+			{{Id: 4, Outcome: predictions.Right}},
+			{{Id: 4, Outcome: predictions.Right}, {Id: 2, Deadline: time.Unix(1500, 0), Outcome: predictions.Wrong}},
+			{{Id: 4, Outcome: predictions.Right}},
+		},
+		Upcoming: [][]*predictions.PredictionSummary{
+			{{Id: 1, Deadline: time.Unix(1500, 0)}, {Id: 2, Deadline: time.Unix(1500, 0)}, {Id: 3, Deadline: time.Unix(2500, 0)}},
+			{{Id: 3, Deadline: time.Unix(2500, 0)}},
+			{},
+		},
+	}
+	sink := &TestSink{}
+	w := NewWatcher(source, 0, sink, nil)
+	polls := []time.Time{time.Unix(1000, 0), time.Unix(2000, 0), time.Unix(3000, 0)}
+	w.now = func() time.Time {
+		return polls[source.calls-1]
+	}
+
+	for i := range polls {
+		err := w.Poll(context.Background())
+		if err != nil {
+			t.Fatalf("Error polling should have been nil, was %s", err)
+		}
+		if i == 0 {
+			continue
+		}
+
+		var passed []int64
+		for _, e := range sink.Events {
+			if e.Kind == DeadlinePassed {
+				passed = append(passed, e.Prediction.Id)
+			}
+		}
+		sink.Events = nil
+		expected := [][]int64{nil, {1}, {3}}[i]
+		if len(passed) != len(expected) || (len(passed) > 0 && passed[0] != expected[0]) {
+			t.Errorf("Incorrect deadlines passed at poll %d; should be %v, was %v", i, expected, passed)
+		}
+	}
+}
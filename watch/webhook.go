@@ -0,0 +1,302 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/jbeshir/predictionbook-extractor/logging"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// WebhookEndpoint is a URL to POST events to. If Kinds is empty, every event is sent.
+type WebhookEndpoint struct {
+	Url    string
+	Secret string
+	Kinds  []EventKind
+}
+
+// webhookQueueLength is the number of deliveries queued for each endpoint before Emit blocks.
+const webhookQueueLength = 1000
+
+// webhookDrainTimeout is how long Close waits for queued deliveries before cancelling them.
+const webhookDrainTimeout = 10 * time.Second
+
+// WebhookSink POSTs each event's record as JSON to its endpoints, signing the body with the endpoint's
+// secret as an HMAC-SHA256 in the X-PredictionBook-Signature header. Failed deliveries are
+// retried with exponential backoff, then appended to the dead letter file as JSON lines.
+//
+// Deliveries are queued and made in the background, in order for each endpoint, so slow or
+// failing endpoints don't hold up the caller or each other. Once the sink's context is
+// cancelled, or Close has waited long enough, retries stop and the remaining deliveries
+// are dead lettered without being attempted.
+type WebhookSink struct {
+	endpoints      []*WebhookEndpoint
+	queues         []chan *webhookDelivery
+	client         *http.Client
+	maxAttempts    int
+	initialBackoff time.Duration
+	deadLetterPath string
+	deadLetterLock sync.Mutex
+	logger         logging.Logger
+
+	ctx          context.Context
+	cancel       context.CancelFunc
+	drainTimeout time.Duration
+
+	workers   sync.WaitGroup
+	closeOnce sync.Once
+	errLock   sync.Mutex
+	err       error
+}
+
+type webhookDelivery struct {
+	id     string
+	kind   EventKind
+	record *EventRecord
+	body   []byte
+}
+
+type webhookDeadLetter struct {
+	Url      string       `json:"url"`
+	Delivery string       `json:"delivery"`
+	Failed   time.Time    `json:"failed"`
+	Error    string       `json:"error"`
+	Event    *EventRecord `json:"event"`
+}
+
+type webhookStatusError struct {
+	status    string
+	retryable bool
+}
+
+func (e *webhookStatusError) Error() string {
+	return "HTTP error: " + e.status
+}
+
+// NewWebhookSink returns a sink delivering to the given endpoints until ctx is cancelled,
+// starting a worker for each. Close must be called to wait for queued deliveries.
+func NewWebhookSink(ctx context.Context, endpoints []*WebhookEndpoint, deadLetterPath string, maxAttempts int, initialBackoff time.Duration, logger logging.Logger) *WebhookSink {
+	s := &WebhookSink{
+		endpoints:      endpoints,
+		client:         &http.Client{Timeout: 30 * time.Second},
+		maxAttempts:    maxAttempts,
+		initialBackoff: initialBackoff,
+		deadLetterPath: deadLetterPath,
+		logger:         logging.OrDiscard(logger),
+		drainTimeout:   webhookDrainTimeout,
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	for _, endpoint := range endpoints {
+		queue := make(chan *webhookDelivery, webhookQueueLength)
+		s.queues = append(s.queues, queue)
+		s.workers.Add(1)
+		go s.run(endpoint, queue)
+	}
+	return s
+}
+
+// Emit queues the event for delivery to every endpoint which wants it, waiting only if an
+// endpoint's queue is full. Delivery failures are recorded in the dead letter file rather
+// than returned; only an earlier failure to record one is an error. Emit must not be
+// called after Close.
+func (s *WebhookSink) Emit(ctx context.Context, event *Event) error {
+	err := s.failure()
+	if err != nil {
+		return err
+	}
+
+	record := NewEventRecord(event)
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	for i, endpoint := range s.endpoints {
+		if !endpoint.wants(event.Kind) {
+			continue
+		}
+
+		select {
+		case s.queues[i] <- &webhookDelivery{id: newDeliveryId(), kind: event.Kind, record: record, body: body}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Close stops accepting events and waits for queued deliveries to be made or dead lettered,
+// returning any failure to record a dead letter. Deliveries still queued after the drain
+// timeout are cancelled.
+func (s *WebhookSink) Close() error {
+	s.closeOnce.Do(func() {
+		for _, queue := range s.queues {
+			close(queue)
+		}
+	})
+
+	done := make(chan bool)
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(s.drainTimeout):
+		s.logger.Warn("Cancelling queued webhook deliveries", logging.DurationKey, s.drainTimeout)
+		s.cancel()
+		<-done
+	}
+	s.cancel()
+
+	return s.failure()
+}
+
+// run delivers an endpoint's queued events until the queue is closed, dead lettering any
+// left once the sink's context is cancelled.
+func (s *WebhookSink) run(endpoint *WebhookEndpoint, queue <-chan *webhookDelivery) {
+	defer s.workers.Done()
+
+	for d := range queue {
+		err := s.ctx.Err()
+		if err == nil {
+			err = s.deliver(s.ctx, endpoint, d.id, d.kind, d.body)
+		}
+		if err == nil {
+			continue
+		}
+		s.logger.Warn("Failed to deliver webhook", "event", d.kind, logging.UrlKey, endpoint.Url, logging.ErrorKey, err)
+
+		err = s.writeDeadLetter(&webhookDeadLetter{
+			Url:      endpoint.Url,
+			Delivery: d.id,
+			Failed:   time.Now(),
+			Error:    err.Error(),
+			Event:    d.record,
+		})
+		if err != nil {
+			s.logger.Error("Failed to record webhook dead letter", logging.UrlKey, endpoint.Url, logging.ErrorKey, err)
+			s.errLock.Lock()
+			if s.err == nil {
+				s.err = err
+			}
+			s.errLock.Unlock()
+		}
+	}
+}
+
+func (s *WebhookSink) failure() error {
+	s.errLock.Lock()
+	defer s.errLock.Unlock()
+	return s.err
+}
+
+func (s *WebhookSink) deliver(ctx context.Context, endpoint *WebhookEndpoint, delivery string, kind EventKind, body []byte) (err error) {
+	backoff := s.initialBackoff
+	for attempt := 0; attempt < s.maxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		err = s.post(ctx, endpoint, delivery, kind, body)
+		if err == nil {
+			return nil
+		}
+		s.logger.Debug("Webhook delivery attempt failed", logging.UrlKey, endpoint.Url, "delivery", delivery, logging.AttemptKey, attempt+1, logging.ErrorKey, err)
+		if statusErr, ok := err.(*webhookStatusError); ok && !statusErr.retryable {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (s *WebhookSink) post(ctx context.Context, endpoint *WebhookEndpoint, delivery string, kind EventKind, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, endpoint.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-PredictionBook-Event", kind.String())
+	req.Header.Set("X-PredictionBook-Delivery", delivery)
+	req.Header.Set("X-PredictionBook-Signature", "sha256="+SignPayload(endpoint.Secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &webhookStatusError{
+			status:    resp.Status,
+			retryable: resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500,
+		}
+	}
+
+	return nil
+}
+
+func (s *WebhookSink) writeDeadLetter(letter *webhookDeadLetter) error {
+	if s.deadLetterPath == "" {
+		return errors.New("webhook delivery to " + letter.Url + " failed: " + letter.Error)
+	}
+
+	s.deadLetterLock.Lock()
+	defer s.deadLetterLock.Unlock()
+
+	f, err := os.OpenFile(s.deadLetterPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	err = json.NewEncoder(f).Encode(letter)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func (e *WebhookEndpoint) wants(kind EventKind) bool {
+	if len(e.Kinds) == 0 {
+		return true
+	}
+
+	for _, k := range e.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// SignPayload returns the hex encoded HMAC-SHA256 of body, which receivers can compare
+// against the X-PredictionBook-Signature header to verify a delivery.
+func SignPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newDeliveryId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
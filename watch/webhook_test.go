@@ -0,0 +1,245 @@
+package watch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/jbeshir/predictionbook-extractor/predictions"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookSinkRetriesAndSigns(t *testing.T) {
+	t.Parallel()
+
+	var mutex sync.Mutex
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		attempts++
+		if attempts == 1 {
+			http.Error(w, "Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.Header.Get("X-PredictionBook-Signature") != "sha256="+SignPayload("secret", body) {
+			t.Errorf("Incorrect signature header, was %s", r.Header.Get("X-PredictionBook-Signature"))
+		}
+		if r.Header.Get("X-PredictionBook-Event") != "prediction_judged" {
+			t.Errorf("Incorrect event header; should be %s, was %s", "prediction_judged", r.Header.Get("X-PredictionBook-Event"))
+		}
+
+		var event EventRecord
+		err := json.Unmarshal(body, &event)
+		if err != nil || event.Prediction.Id != 7 {
+			t.Errorf("Incorrect payload, was %s", body)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(context.Background(), []*WebhookEndpoint{{Url: server.URL, Secret: "secret"}}, "", 3, time.Millisecond, nil)
+	err := sink.Emit(context.Background(), &Event{
+		Kind:       PredictionJudged,
+		Prediction: &predictions.PredictionSummary{Id: 7, Outcome: predictions.Right},
+	})
+	if err != nil {
+		t.Errorf("Error should have been nil, was %s", err)
+	}
+	err = sink.Close()
+	if err != nil {
+		t.Errorf("Error closing should have been nil, was %s", err)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if attempts != 2 {
+		t.Errorf("Incorrect number of delivery attempts; should be %d, was %d", 2, attempts)
+	}
+}
+
+func TestWebhookSinkDeadLetter(t *testing.T) {
+	t.Parallel()
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, "Unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "webhook")
+	if err != nil {
+		t.Fatalf("Couldn't create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	deadLetterPath := filepath.Join(dir, "deadletter.jsonl")
+
+	sink := NewWebhookSink(context.Background(), []*WebhookEndpoint{
+		{Url: server.URL, Secret: "secret"},
+		{Url: server.URL, Secret: "secret", Kinds: []EventKind{NewEstimate}},
+	}, deadLetterPath, 3, time.Millisecond, nil)
+	err = sink.Emit(context.Background(), &Event{
+		Kind:       PredictionCreated,
+		Prediction: &predictions.PredictionSummary{Id: 7},
+	})
+	if err != nil {
+		t.Errorf("Error should have been nil, was %s", err)
+	}
+	err = sink.Close()
+	if err != nil {
+		t.Errorf("Error closing should have been nil, was %s", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Incorrect number of delivery attempts; should be %d, was %d", 3, attempts)
+	}
+
+	f, err := os.Open(deadLetterPath)
+	if err != nil {
+		t.Fatalf("Couldn't open dead letter file: %s", err)
+	}
+	defer f.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var letter map[string]interface{}
+		err := json.Unmarshal(scanner.Bytes(), &letter)
+		if err != nil {
+			t.Errorf("Couldn't decode dead letter: %s", err)
+		}
+		if letter["url"] != server.URL {
+			t.Errorf("Incorrect dead letter URL; should be %s, was %v", server.URL, letter["url"])
+		}
+		lines++
+	}
+	if lines != 1 {
+		t.Errorf("Incorrect number of dead letters; should be %d, was %d", 1, lines)
+	}
+}
+
+func TestWebhookSinkDeliversInBackground(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(context.Background(), []*WebhookEndpoint{{Url: server.URL, Secret: "secret"}}, "", 3, time.Millisecond, nil)
+	for i := 0; i < 3; i++ {
+		err := sink.Emit(context.Background(), &Event{
+			Kind:       PredictionCreated,
+			Prediction: &predictions.PredictionSummary{Id: int64(i)},
+		})
+		if err != nil {
+			t.Fatalf("Error should have been nil, was %s", err)
+		}
+	}
+
+	// Every event was accepted while the endpoint was stalled on the first
+	close(release)
+	err := sink.Close()
+	if err != nil {
+		t.Errorf("Error closing should have been nil, was %s", err)
+	}
+}
+
+func TestWebhookSinkDeadLetterFailure(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	// Without a dead letter file, failed deliveries can't be recorded
+	sink := NewWebhookSink(context.Background(), []*WebhookEndpoint{{Url: server.URL, Secret: "secret"}}, "", 1, time.Millisecond, nil)
+	err := sink.Emit(context.Background(), &Event{Kind: PredictionCreated, Prediction: &predictions.PredictionSummary{Id: 7}})
+	if err != nil {
+		t.Errorf("Error should have been nil, was %s", err)
+	}
+	err = sink.Close()
+	if err == nil {
+		t.Errorf("Expected error closing after failing to record a dead letter")
+	}
+}
+
+func TestWebhookSinkCloseCancelsRetries(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "webhook")
+	if err != nil {
+		t.Fatalf("Couldn't create temporary directory: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	deadLetterPath := filepath.Join(dir, "deadletter.jsonl")
+
+	// Retries would take an hour without the drain timeout
+	sink := NewWebhookSink(context.Background(), []*WebhookEndpoint{{Url: server.URL, Secret: "secret"}}, deadLetterPath, 5, time.Hour, nil)
+	sink.drainTimeout = 10 * time.Millisecond
+	for i := 0; i < 2; i++ {
+		err := sink.Emit(context.Background(), &Event{Kind: PredictionCreated, Prediction: &predictions.PredictionSummary{Id: int64(i)}})
+		if err != nil {
+			t.Fatalf("Error should have been nil, was %s", err)
+		}
+	}
+
+	start := time.Now()
+	err = sink.Close()
+	if err != nil {
+		t.Errorf("Error closing should have been nil, was %s", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Close should have cancelled retries, took %s", time.Since(start))
+	}
+
+	content, err := ioutil.ReadFile(deadLetterPath)
+	if err != nil {
+		t.Fatalf("Couldn't read dead letter file: %s", err)
+	}
+	if lines := strings.Count(string(content), "\n"); lines != 2 {
+		t.Errorf("Incorrect number of dead letters; should be %d, was %d", 2, lines)
+	}
+}
+
+func TestWebhookSinkContextCancelled(t *testing.T) {
+	t.Parallel()
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Undelivered events can't be dead lettered without a file, so are reported by Close
+	sink := NewWebhookSink(ctx, []*WebhookEndpoint{{Url: server.URL, Secret: "secret"}}, "", 5, time.Hour, nil)
+	err := sink.Emit(context.Background(), &Event{Kind: PredictionCreated, Prediction: &predictions.PredictionSummary{Id: 7}})
+	if err != nil {
+		t.Fatalf("Error should have been nil, was %s", err)
+	}
+	err = sink.Close()
+	if err == nil {
+		t.Errorf("Expected error closing after deliveries were cancelled")
+	}
+	if attempts != 0 {
+		t.Errorf("Incorrect number of delivery attempts; should be %d, was %d", 0, attempts)
+	}
+}